@@ -0,0 +1,78 @@
+package api
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ttlLRUCache is a small in-process, size- and TTL-bounded cache. It's deliberately
+// generic-free (predates generics being idiomatic in this codebase) and stores
+// interface{} values; callers type-assert on read.
+type ttlLRUCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxItems int
+	items    map[string]*list.Element
+	order    *list.List // front = most recently used
+}
+
+type ttlLRUEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+func newTTLLRUCache(maxItems int, ttl time.Duration) *ttlLRUCache {
+	return &ttlLRUCache{
+		ttl:      ttl,
+		maxItems: maxItems,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached value for key and whether it was a hit. An expired entry counts
+// as a miss and is evicted on the way out.
+func (c *ttlLRUCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*ttlLRUEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *ttlLRUCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*ttlLRUEntry).value = value
+		el.Value.(*ttlLRUEntry).expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&ttlLRUEntry{key: key, value: value, expiresAt: time.Now().Add(c.ttl)})
+	c.items[key] = el
+
+	for c.order.Len() > c.maxItems {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*ttlLRUEntry).key)
+	}
+}
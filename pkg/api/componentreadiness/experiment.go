@@ -0,0 +1,197 @@
+package componentreadiness
+
+import (
+	"context"
+	"fmt"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+)
+
+// reservedExperimentArmNames are resolved directly against JobRunTestReportStatus rather
+// than ExtraArmStatus, so a caller-supplied RequestAdvancedOptions.ExperimentArms entry
+// can't shadow one of them.
+var reservedExperimentArmNames = map[string]bool{
+	"base":         true,
+	"baseOverride": true,
+	"sample":       true,
+}
+
+// ExperimentSpec describes an N-arm experiment: the sample under test against one or more
+// base arms. "base" (today's BaseRelease) and, if configured, "baseOverride" are always
+// included; RequestAdvancedOptions.ExperimentArms adds any further arms a caller wants
+// fetched and compared, e.g. a second base release or a synthetic control population --
+// each is fetched by Release/Start/End exactly like "base" is, so arbitrary arms need no
+// bespoke fetch path.
+type ExperimentSpec struct {
+	Arms []crtype.ExperimentArm
+}
+
+// defaultExperimentSpec builds the ExperimentSpec for this generator: today's BaseRelease,
+// optional BaseOverrideRelease, and any RequestAdvancedOptions.ExperimentArms the caller
+// supplied. Both GetTestDetails (via GenerateTestDetailsReport) and GetTestExperiment use
+// it, so RequestOptions' existing shape is all a caller needs for either entrypoint.
+func (c *componentReportGenerator) defaultExperimentSpec() ExperimentSpec {
+	arms := []crtype.ExperimentArm{
+		{Name: "base", Release: c.BaseRelease.Release, Start: c.BaseRelease.Start, End: c.BaseRelease.End},
+	}
+	if c.BaseOverrideRelease.Release != "" && c.BaseOverrideRelease.Release != c.BaseRelease.Release {
+		arms = append(arms, crtype.ExperimentArm{
+			Name:    "baseOverride",
+			Release: c.BaseOverrideRelease.Release,
+			Start:   c.BaseOverrideRelease.Start,
+			End:     c.BaseOverrideRelease.End,
+		})
+	}
+	arms = append(arms, c.ExperimentArms...)
+	return ExperimentSpec{Arms: arms}
+}
+
+// aggregateCounts sums success/failure counts across every job run row in status,
+// folding flakes in according to flakeAsFailure the same way internalGenerateTestDetailsReport
+// does, so pairwise arm comparisons use the same notion of "passed" as the rest of the report.
+func aggregateCounts(status map[string][]crtype.JobRunTestStatusRow, flakeAsFailure bool) (success, failure int) {
+	for _, rows := range status {
+		for _, row := range rows {
+			f := getFailureCount(row)
+			if flakeAsFailure {
+				success += row.SuccessCount
+				failure += f + row.FlakeCount
+			} else {
+				success += row.SuccessCount + row.FlakeCount
+				failure += f
+			}
+		}
+	}
+	return
+}
+
+// armStatusByName resolves each of spec's arms to its fetched JobRunTestStatusRow map:
+// "base" and "baseOverride" come from the status's dedicated fields, and every other arm
+// name comes from ExtraArmStatus, which getJobRunTestStatusFromBigQuery populates by
+// fetching each of c.ExperimentArms the same way it fetches "base". An arm with no
+// corresponding fetched status (an unrecognized name slipped into spec some other way)
+// is reported as an error rather than silently treated as empty.
+func armStatusByName(spec ExperimentSpec, status crtype.JobRunTestReportStatus) (map[string]map[string][]crtype.JobRunTestStatusRow, []error) {
+	resolved := map[string]map[string][]crtype.JobRunTestStatusRow{
+		"base":         status.BaseStatus,
+		"baseOverride": status.BaseOverrideStatus,
+		"sample":       status.SampleStatus,
+	}
+
+	var errs []error
+	for _, arm := range spec.Arms {
+		if _, ok := resolved[arm.Name]; ok {
+			continue
+		}
+		armStatus, ok := status.ExtraArmStatus[arm.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown experiment arm %q", arm.Name))
+			continue
+		}
+		resolved[arm.Name] = armStatus
+	}
+	return resolved, errs
+}
+
+// GenerateTestExperiment runs spec's arms against the sample and returns a
+// ReportTestExperiment: a ReportTestDetails per arm, plus a pairwise significance matrix
+// covering the sample and every arm. Arms beyond "base"/"baseOverride" are fetched via
+// RequestAdvancedOptions.ExperimentArms (see getJobRunTestStatusFromBigQuery), so this
+// supports genuinely arbitrary N-arm comparisons, e.g. a sample against a 4.14 base, a
+// 4.15 base, and a synthetic no-regressions-allowed base in one call.
+func (c *componentReportGenerator) GenerateTestExperiment(ctx context.Context, spec ExperimentSpec) (crtype.ReportTestExperiment, []error) {
+	if c.TestID == "" {
+		return crtype.ReportTestExperiment{}, []error{fmt.Errorf("test_id has to be defined for test details")}
+	}
+	for _, v := range c.DBGroupBy.List() {
+		if _, ok := c.RequestedVariants[v]; !ok {
+			return crtype.ReportTestExperiment{}, []error{fmt.Errorf("all dbGroupBy variants have to be defined for test details: %s is missing", v)}
+		}
+	}
+	seen := make(map[string]bool, len(spec.Arms))
+	for _, arm := range spec.Arms {
+		if seen[arm.Name] {
+			return crtype.ReportTestExperiment{}, []error{fmt.Errorf("duplicate experiment arm %q", arm.Name)}
+		}
+		seen[arm.Name] = true
+	}
+	for _, arm := range c.ExperimentArms {
+		if reservedExperimentArmNames[arm.Name] {
+			return crtype.ReportTestExperiment{}, []error{fmt.Errorf("experiment arm %q is reserved", arm.Name)}
+		}
+	}
+
+	componentJobRunTestReportStatus, errs := c.GenerateJobRunTestReportStatus(ctx)
+	if len(errs) > 0 {
+		return crtype.ReportTestExperiment{}, errs
+	}
+
+	bqs := NewBigQueryRegressionStore(c.client)
+	allRegressions, err := bqs.ListCurrentRegressions(ctx)
+	if err != nil {
+		return crtype.ReportTestExperiment{}, append(errs, err)
+	}
+	c.openRegressions = FilterRegressionsForRelease(allRegressions, c.SampleRelease.Release)
+
+	result := crtype.ReportTestExperiment{
+		ReportTestIdentification: crtype.ReportTestIdentification{
+			RowIdentification: crtype.RowIdentification{
+				Component:  c.Component,
+				Capability: c.Capability,
+				TestID:     c.TestID,
+			},
+			ColumnIdentification: crtype.ColumnIdentification{
+				Variants: c.RequestedVariants,
+			},
+		},
+		GeneratedAt: componentJobRunTestReportStatus.GeneratedAt,
+	}
+
+	armStatus, armErrs := armStatusByName(spec, componentJobRunTestReportStatus)
+	if len(armErrs) > 0 {
+		return crtype.ReportTestExperiment{}, armErrs
+	}
+
+	for _, arm := range spec.Arms {
+		// internalGenerateTestDetailsReport deletes matched entries from sampleStatus as it
+		// consumes them, so give each arm its own copy, same as the old override plumbing did.
+		sampleStatus := make(map[string][]crtype.JobRunTestStatusRow, len(componentJobRunTestReportStatus.SampleStatus))
+		for k, v := range componentJobRunTestReportStatus.SampleStatus {
+			sampleStatus[k] = v
+		}
+		start, end := arm.Start, arm.End
+		report := c.internalGenerateTestDetailsReport(ctx, armStatus[arm.Name], arm.Release, &start, &end, sampleStatus)
+		report.GeneratedAt = componentJobRunTestReportStatus.GeneratedAt
+		result.Arms = append(result.Arms, crtype.ArmReport{Arm: arm, Report: report})
+	}
+
+	// "sample" joins the matrix as its own comparison point alongside the base arms, so
+	// even the common case of a single base arm (no override configured) still yields the
+	// sample-vs-base comparison that's the entire point of the report, instead of an empty
+	// matrix.
+	const sampleArmName = "sample"
+	names := make([]string, 0, len(result.Arms)+1)
+	for _, arm := range result.Arms {
+		names = append(names, arm.Arm.Name)
+	}
+	names = append(names, sampleArmName)
+
+	sigTest := selectSignificanceTest(c.RequestAdvancedOptions.SignificanceTestBackend)
+	for i := 0; i < len(names); i++ {
+		for j := i + 1; j < len(names); j++ {
+			nameA, nameB := names[i], names[j]
+			aSuccess, aFailure := aggregateCounts(armStatus[nameA], c.FlakeAsFailure)
+			bSuccess, bFailure := aggregateCounts(armStatus[nameB], c.FlakeAsFailure)
+			pValue, significant, effect := sigTest.Compare(aSuccess, aFailure, bSuccess, bFailure, float64(c.Confidence))
+			result.SignificanceMatrix = append(result.SignificanceMatrix, crtype.PairwiseComparison{
+				ArmA:        nameA,
+				ArmB:        nameB,
+				PValue:      pValue,
+				Significant: significant,
+				Effect:      effect,
+			})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package componentreadiness
+
+import (
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/regressionallowances"
+)
+
+// componentReportGenerator carries everything needed to produce a component readiness
+// report for one request: the BigQuery client, the release/test/variant identification
+// pulled from the request, and the advanced tuning knobs (significance backend, retry
+// budget, concurrency cap) a caller may override.
+type componentReportGenerator struct {
+	client    *bigquery.Client
+	prowURL   string
+	gcsBucket string
+
+	// cacheOption is forwarded to api.GetDataFromCacheOrGenerate as-is.
+	cacheOption interface{}
+
+	BaseRelease         crtype.RequestReleaseOptions
+	BaseOverrideRelease crtype.RequestReleaseOptions
+	SampleRelease       crtype.RequestReleaseOptions
+
+	crtype.RequestTestIdentificationOptions
+	crtype.RequestVariantOptions
+	crtype.RequestAdvancedOptions
+
+	// openRegressions is populated from the regression store once per report and
+	// consulted when assessing a test's status.
+	openRegressions []*regressionallowances.IntentionalRegression
+}
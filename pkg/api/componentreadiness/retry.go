@@ -0,0 +1,104 @@
+package componentreadiness
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+
+	"github.com/openshift/sippy/pkg/retry"
+)
+
+// transientBigQueryReasons are the googleapi.Error Reason/Message substrings we consider
+// safe to retry -- rate limiting and backend hiccups -- as opposed to e.g. a malformed
+// query or a permissions error, which will just fail the same way every time.
+var transientBigQueryReasons = []string{
+	"ratelimitexceeded",
+	"backenderror",
+	"internalerror",
+}
+
+// isTransientBigQueryError reports whether err is worth retrying: a 5xx from BigQuery, a
+// rate-limit/backend-error reason, or a context deadline exceeded with retry budget still
+// remaining (the caller's ctx isn't itself expired). Syntax and permission errors are not
+// retryable and fail the first time.
+func isTransientBigQueryError(ctx context.Context) retry.IsRetryable {
+	return func(err error) bool {
+		if err == nil {
+			return false
+		}
+		if ctx.Err() != nil {
+			// our own context is done, there's no point retrying
+			return false
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) {
+			if apiErr.Code >= 500 && apiErr.Code < 600 {
+				return true
+			}
+			texts := []string{apiErr.Message}
+			for _, item := range apiErr.Errors {
+				texts = append(texts, item.Reason, item.Message)
+			}
+			for _, text := range texts {
+				reason := strings.ToLower(text)
+				for _, r := range transientBigQueryReasons {
+					if strings.Contains(reason, r) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+
+		lower := strings.ToLower(err.Error())
+		for _, r := range transientBigQueryReasons {
+			if strings.Contains(lower, r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// classifyErrorKind gives a short, stable label for the last error seen in a retry loop,
+// suitable for recording on a report. It returns "" for a nil error, which is what
+// retry.Do reports as LastErr once an attempt succeeds.
+func classifyErrorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "deadline_exceeded"
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		if apiErr.Code >= 500 {
+			return "backend_error"
+		}
+		lower := strings.ToLower(apiErr.Message)
+		for _, r := range transientBigQueryReasons {
+			if strings.Contains(lower, r) {
+				return r
+			}
+		}
+	}
+
+	return "other"
+}
+
+// retryOptions resolves the retry budget for this report: callers (e.g. cron report
+// generation) can request a more aggressive budget than the interactive default via
+// RequestOptions.AdvancedOption.RetryOptions.
+func (c *componentReportGenerator) retryOptions() retry.Options {
+	if c.RequestAdvancedOptions.RetryOptions != (retry.Options{}) {
+		return c.RequestAdvancedOptions.RetryOptions
+	}
+	return retry.DefaultOptions()
+}
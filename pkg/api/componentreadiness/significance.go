@@ -0,0 +1,149 @@
+package componentreadiness
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/aclements/go-moremath/stats"
+	fet "github.com/glycerine/golang-fisher-exact"
+)
+
+// SignificanceTest compares a sample population of test run outcomes against a base
+// population and reports whether the difference is statistically significant. Multiple
+// implementations exist so callers can pick one appropriate to their data -- e.g. bootstrap
+// when tests have variable retries per job run, or Mann-Whitney when a distributional shift
+// matters more than raw counts.
+type SignificanceTest interface {
+	// Name identifies the backend in report output, e.g. "fishers_exact".
+	Name() string
+	Compare(sampleSuccess, sampleFail, baseSuccess, baseFail int, confidence float64) (pValue float64, significant bool, effect float64)
+}
+
+// FisherExactSignificanceTest wraps the existing Fisher's exact test so it can be selected
+// through the same SignificanceTest interface as the newer backends.
+type FisherExactSignificanceTest struct{}
+
+func (FisherExactSignificanceTest) Name() string { return "fishers_exact" }
+
+func (FisherExactSignificanceTest) Compare(sampleSuccess, sampleFail, baseSuccess, baseFail int, confidence float64) (float64, bool, float64) {
+	_, _, r, _ := fet.FisherExactTest(sampleFail, sampleSuccess, baseFail, baseSuccess)
+	return r, r < 1-confidence/100, successRate(sampleSuccess, sampleFail) - successRate(baseSuccess, baseFail)
+}
+
+func successRate(success, fail int) float64 {
+	total := success + fail
+	if total == 0 {
+		return 0
+	}
+	return float64(success) / float64(total)
+}
+
+func outcomes(success, fail int) []float64 {
+	out := make([]float64, 0, success+fail)
+	for i := 0; i < success; i++ {
+		out = append(out, 1)
+	}
+	for i := 0; i < fail; i++ {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// BootstrapSignificanceTest resamples per-job-run pass/fail outcomes with replacement
+// Iterations times, computes the observed difference in means, and returns the fraction of
+// resampled differences at least as extreme as the observed one. Both groups are resampled
+// from the pooled sample+base outcomes, i.e. under the hypothesis of no difference.
+type BootstrapSignificanceTest struct {
+	Iterations int
+	Seed       int64
+}
+
+// NewBootstrapSignificanceTest returns a bootstrap test with the defaults used by the
+// component readiness generator: 10,000 resamples, seeded for reproducibility.
+func NewBootstrapSignificanceTest() *BootstrapSignificanceTest {
+	return &BootstrapSignificanceTest{Iterations: 10000, Seed: 1}
+}
+
+func (b *BootstrapSignificanceTest) Name() string { return "bootstrap" }
+
+func (b *BootstrapSignificanceTest) Compare(sampleSuccess, sampleFail, baseSuccess, baseFail int, confidence float64) (float64, bool, float64) {
+	sampleOutcomes := outcomes(sampleSuccess, sampleFail)
+	baseOutcomes := outcomes(baseSuccess, baseFail)
+	if len(sampleOutcomes) == 0 || len(baseOutcomes) == 0 {
+		return 1, false, 0
+	}
+
+	observedDiff := mean(sampleOutcomes) - mean(baseOutcomes)
+	pooled := append(append(make([]float64, 0, len(sampleOutcomes)+len(baseOutcomes)), sampleOutcomes...), baseOutcomes...)
+
+	rng := rand.New(rand.NewSource(b.Seed))
+	atLeastAsExtreme := 0
+	for i := 0; i < b.Iterations; i++ {
+		diff := mean(resample(rng, pooled, len(sampleOutcomes))) - mean(resample(rng, pooled, len(baseOutcomes)))
+		if math.Abs(diff) >= math.Abs(observedDiff) {
+			atLeastAsExtreme++
+		}
+	}
+
+	pValue := float64(atLeastAsExtreme) / float64(b.Iterations)
+	return pValue, pValue < 1-confidence/100, observedDiff
+}
+
+func resample(rng *rand.Rand, population []float64, size int) []float64 {
+	resampled := make([]float64, size)
+	for i := range resampled {
+		resampled[i] = population[rng.Intn(len(population))]
+	}
+	return resampled
+}
+
+// MannWhitneySignificanceTest compares the per-job-run success-rate distributions of the
+// sample and base populations with a Mann-Whitney U test, for when a distributional shift
+// matters more than raw pass/fail counts.
+type MannWhitneySignificanceTest struct{}
+
+func (MannWhitneySignificanceTest) Name() string { return "mann_whitney" }
+
+func (MannWhitneySignificanceTest) Compare(sampleSuccess, sampleFail, baseSuccess, baseFail int, confidence float64) (float64, bool, float64) {
+	sampleOutcomes := outcomes(sampleSuccess, sampleFail)
+	baseOutcomes := outcomes(baseSuccess, baseFail)
+	if len(sampleOutcomes) == 0 || len(baseOutcomes) == 0 {
+		return 1, false, 0
+	}
+
+	effect := mean(sampleOutcomes) - mean(baseOutcomes)
+	result, err := stats.MannWhitneyUTest(sampleOutcomes, baseOutcomes, stats.LocationDiffers)
+	if err != nil {
+		return 1, false, effect
+	}
+	return result.P, result.P < 1-confidence/100, effect
+}
+
+// significanceTestBackends indexes the available backends by the name a caller sets on
+// RequestOptions.AdvancedOption.SignificanceTestBackend. An empty/unrecognized name falls
+// back to Fisher's exact, matching today's hard-wired behavior.
+var significanceTestBackends = map[string]func() SignificanceTest{
+	"fishers_exact": func() SignificanceTest { return FisherExactSignificanceTest{} },
+	"bootstrap":     func() SignificanceTest { return NewBootstrapSignificanceTest() },
+	"mann_whitney":  func() SignificanceTest { return MannWhitneySignificanceTest{} },
+}
+
+// selectSignificanceTest resolves the backend requested for this report, defaulting to
+// Fisher's exact.
+func selectSignificanceTest(backend string) SignificanceTest {
+	if factory, ok := significanceTestBackends[backend]; ok {
+		return factory()
+	}
+	return FisherExactSignificanceTest{}
+}
@@ -0,0 +1,49 @@
+package componentreadiness
+
+import "testing"
+
+func TestBootstrapSignificanceTestReproducibleAcrossRepeatedCalls(t *testing.T) {
+	test := &BootstrapSignificanceTest{Iterations: 500, Seed: 42}
+
+	pValue1, significant1, effect1 := test.Compare(40, 60, 90, 10, 95)
+	pValue2, significant2, effect2 := test.Compare(40, 60, 90, 10, 95)
+
+	if pValue1 != pValue2 {
+		t.Errorf("pValue = %v then %v, want identical results for the same seed", pValue1, pValue2)
+	}
+	if significant1 != significant2 {
+		t.Errorf("significant = %v then %v, want identical results for the same seed", significant1, significant2)
+	}
+	if effect1 != effect2 {
+		t.Errorf("effect = %v then %v, want identical results for the same seed", effect1, effect2)
+	}
+}
+
+func TestSignificanceTestsDetectLargeObservedDifference(t *testing.T) {
+	for _, test := range []SignificanceTest{
+		FisherExactSignificanceTest{},
+		NewBootstrapSignificanceTest(),
+		MannWhitneySignificanceTest{},
+	} {
+		_, significant, effect := test.Compare(10, 90, 90, 10, 95)
+		if !significant {
+			t.Errorf("%s: significant = false for a 10%% vs 90%% success rate, want true", test.Name())
+		}
+		if effect >= 0 {
+			t.Errorf("%s: effect = %v, want negative (sample success rate well below base)", test.Name(), effect)
+		}
+	}
+}
+
+func TestSignificanceTestsIgnoreNoDifference(t *testing.T) {
+	for _, test := range []SignificanceTest{
+		FisherExactSignificanceTest{},
+		NewBootstrapSignificanceTest(),
+		MannWhitneySignificanceTest{},
+	} {
+		_, significant, _ := test.Compare(50, 50, 50, 50, 95)
+		if significant {
+			t.Errorf("%s: significant = true for identical sample/base distributions, want false", test.Name())
+		}
+	}
+}
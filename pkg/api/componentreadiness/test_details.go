@@ -5,23 +5,55 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	bigquery2 "cloud.google.com/go/bigquery"
-	fet "github.com/glycerine/golang-fisher-exact"
 	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/sippy/pkg/api"
 	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
 	"github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/concurrency"
 	"github.com/openshift/sippy/pkg/regressionallowances"
+	"github.com/openshift/sippy/pkg/retry"
 	"github.com/openshift/sippy/pkg/util/param"
 )
 
 func GetTestDetails(ctx context.Context, client *bigquery.Client, prowURL, gcsBucket string, reqOptions crtype.RequestOptions,
 ) (crtype.ReportTestDetails, []error) {
-	generator := componentReportGenerator{
+	generator := newComponentReportGenerator(client, prowURL, gcsBucket, reqOptions)
+
+	return api.GetDataFromCacheOrGenerate[crtype.ReportTestDetails](
+		ctx,
+		generator.client.Cache,
+		generator.cacheOption,
+		generator.GetComponentReportCacheKey(ctx, "TestDetailsReport~"),
+		generator.GenerateTestDetailsReport,
+		crtype.ReportTestDetails{})
+}
+
+// GetTestExperiment is GetTestDetails' N-arm counterpart: it returns every arm named by
+// reqOptions (the built-in "base"/"baseOverride" plus any
+// AdvancedOption.ExperimentArms) compared against the sample, along with a pairwise
+// significance matrix across all of them, instead of collapsing down to a single
+// ReportTestDetails.
+func GetTestExperiment(ctx context.Context, client *bigquery.Client, prowURL, gcsBucket string, reqOptions crtype.RequestOptions,
+) (crtype.ReportTestExperiment, []error) {
+	generator := newComponentReportGenerator(client, prowURL, gcsBucket, reqOptions)
+
+	return api.GetDataFromCacheOrGenerate[crtype.ReportTestExperiment](
+		ctx,
+		generator.client.Cache,
+		generator.cacheOption,
+		generator.GetComponentReportCacheKey(ctx, "TestExperiment~"),
+		func(ctx context.Context) (crtype.ReportTestExperiment, []error) {
+			return generator.GenerateTestExperiment(ctx, generator.defaultExperimentSpec())
+		},
+		crtype.ReportTestExperiment{})
+}
+
+func newComponentReportGenerator(client *bigquery.Client, prowURL, gcsBucket string, reqOptions crtype.RequestOptions) componentReportGenerator {
+	return componentReportGenerator{
 		client:                           client,
 		prowURL:                          prowURL,
 		gcsBucket:                        gcsBucket,
@@ -33,66 +65,41 @@ func GetTestDetails(ctx context.Context, client *bigquery.Client, prowURL, gcsBu
 		RequestVariantOptions:            reqOptions.VariantOption,
 		RequestAdvancedOptions:           reqOptions.AdvancedOption,
 	}
-
-	return api.GetDataFromCacheOrGenerate[crtype.ReportTestDetails](
-		ctx,
-		generator.client.Cache,
-		generator.cacheOption,
-		generator.GetComponentReportCacheKey(ctx, "TestDetailsReport~"),
-		generator.GenerateTestDetailsReport,
-		crtype.ReportTestDetails{})
 }
 
+// GenerateTestDetailsReport resolves the base/baseOverride experiment for today's callers
+// and adapts it back to the single-report shape they expect, ignoring any
+// AdvancedOption.ExperimentArms beyond those two (use GetTestExperiment to see every arm).
+// The actual comparison work lives in GenerateTestExperiment, which handles any number of
+// arms.
 func (c *componentReportGenerator) GenerateTestDetailsReport(ctx context.Context) (crtype.ReportTestDetails, []error) {
-	if c.TestID == "" {
-		return crtype.ReportTestDetails{}, []error{fmt.Errorf("test_id has to be defined for test details")}
-	}
-	for _, v := range c.DBGroupBy.List() {
-		if _, ok := c.RequestedVariants[v]; !ok {
-			return crtype.ReportTestDetails{}, []error{fmt.Errorf("all dbGroupBy variants have to be defined for test details: %s is missing", v)}
-		}
-	}
-
-	componentJobRunTestReportStatus, errs := c.GenerateJobRunTestReportStatus(ctx)
+	experiment, errs := c.GenerateTestExperiment(ctx, c.defaultExperimentSpec())
 	if len(errs) > 0 {
 		return crtype.ReportTestDetails{}, errs
 	}
-	var err error
-	bqs := NewBigQueryRegressionStore(c.client)
-	allRegressions, err := bqs.ListCurrentRegressions(ctx)
-	if err != nil {
-		errs = append(errs, err)
-		return crtype.ReportTestDetails{}, errs
-	}
 
-	var baseOverrideReport *crtype.ReportTestDetails
-	if c.BaseOverrideRelease.Release != "" && c.BaseOverrideRelease.Release != c.BaseRelease.Release {
-		// because internalGenerateTestDetailsReport modifies SampleStatus we need to copy it here
-		overrideSampleStatus := map[string][]crtype.JobRunTestStatusRow{}
-		for k, v := range componentJobRunTestReportStatus.SampleStatus {
-			overrideSampleStatus[k] = v
+	var baseReport, overrideReport *crtype.ReportTestDetails
+	for i := range experiment.Arms {
+		switch experiment.Arms[i].Arm.Name {
+		case "base":
+			baseReport = &experiment.Arms[i].Report
+		case "baseOverride":
+			overrideReport = &experiment.Arms[i].Report
 		}
-
-		overrideReport := c.internalGenerateTestDetailsReport(ctx, componentJobRunTestReportStatus.BaseOverrideStatus, c.BaseOverrideRelease.Release, &c.BaseOverrideRelease.Start, &c.BaseOverrideRelease.End, overrideSampleStatus)
-		// swap out the base dates for the override
-		overrideReport.GeneratedAt = componentJobRunTestReportStatus.GeneratedAt
-		baseOverrideReport = &overrideReport
+	}
+	if baseReport == nil {
+		return crtype.ReportTestDetails{}, []error{fmt.Errorf("experiment produced no base arm result")}
 	}
 
-	c.openRegressions = FilterRegressionsForRelease(allRegressions, c.SampleRelease.Release)
-	report := c.internalGenerateTestDetailsReport(ctx, componentJobRunTestReportStatus.BaseStatus, c.BaseRelease.Release, &c.BaseRelease.Start, &c.BaseRelease.End, componentJobRunTestReportStatus.SampleStatus)
-	report.GeneratedAt = componentJobRunTestReportStatus.GeneratedAt
-
-	if baseOverrideReport != nil {
-		baseOverrideReport.BaseOverrideReport = crtype.ReportTestOverride{
-			ReportTestStats: report.ReportTestStats,
-			JobStats:        report.JobStats,
+	if overrideReport != nil {
+		overrideReport.BaseOverrideReport = crtype.ReportTestOverride{
+			ReportTestStats: baseReport.ReportTestStats,
+			JobStats:        baseReport.JobStats,
 		}
-
-		return *baseOverrideReport, nil
+		return *overrideReport, nil
 	}
 
-	return report, nil
+	return *baseReport, nil
 }
 
 func (c *componentReportGenerator) GenerateJobRunTestReportStatus(ctx context.Context) (crtype.JobRunTestReportStatus, []error) {
@@ -143,12 +150,19 @@ func (c *componentReportGenerator) getBaseJobRunTestStatus(
 		baseEnd,
 	)
 
-	jobRunTestStatus, errs := api.GetDataFromCacheOrGenerate[crtype.JobRunTestReportStatus](
-		ctx,
-		generator.ComponentReportGenerator.client.Cache, generator.cacheOption,
-		api.GetPrefixedCacheKey("BaseJobRunTestStatus~", generator),
-		generator.queryTestStatus,
-		crtype.JobRunTestReportStatus{})
+	var jobRunTestStatus crtype.JobRunTestReportStatus
+	var errs []error
+	retryResult := retry.Do(ctx, c.retryOptions(), isTransientBigQueryError(ctx), func(ctx context.Context) error {
+		jobRunTestStatus, errs = api.GetDataFromCacheOrGenerate[crtype.JobRunTestReportStatus](
+			ctx,
+			generator.ComponentReportGenerator.client.Cache, generator.cacheOption,
+			api.GetPrefixedCacheKey("BaseJobRunTestStatus~", generator),
+			generator.queryTestStatus,
+			crtype.JobRunTestReportStatus{})
+		return firstError(errs)
+	})
+	jobRunTestStatus.RetryCount = retryResult.Attempts - 1
+	jobRunTestStatus.LastErrorKind = classifyErrorKind(retryResult.LastErr)
 
 	if len(errs) > 0 {
 		return nil, errs
@@ -161,12 +175,19 @@ func (c *componentReportGenerator) getSampleJobRunTestStatus(ctx context.Context
 
 	generator := newSampleTestDetailsQueryGenerator(c, allJobVariants)
 
-	jobRunTestStatus, errs := api.GetDataFromCacheOrGenerate[crtype.JobRunTestReportStatus](
-		ctx,
-		c.client.Cache, c.cacheOption,
-		api.GetPrefixedCacheKey("SampleJobRunTestStatus~", generator),
-		generator.queryTestStatus,
-		crtype.JobRunTestReportStatus{})
+	var jobRunTestStatus crtype.JobRunTestReportStatus
+	var errs []error
+	retryResult := retry.Do(ctx, c.retryOptions(), isTransientBigQueryError(ctx), func(ctx context.Context) error {
+		jobRunTestStatus, errs = api.GetDataFromCacheOrGenerate[crtype.JobRunTestReportStatus](
+			ctx,
+			c.client.Cache, c.cacheOption,
+			api.GetPrefixedCacheKey("SampleJobRunTestStatus~", generator),
+			generator.queryTestStatus,
+			crtype.JobRunTestReportStatus{})
+		return firstError(errs)
+	})
+	jobRunTestStatus.RetryCount = retryResult.Attempts - 1
+	jobRunTestStatus.LastErrorKind = classifyErrorKind(retryResult.LastErr)
 
 	if len(errs) > 0 {
 		return nil, errs
@@ -175,63 +196,98 @@ func (c *componentReportGenerator) getSampleJobRunTestStatus(ctx context.Context
 	return jobRunTestStatus.SampleStatus, nil
 }
 
+// defaultMaxConcurrentQueries caps how many of one report's base/sample/override/experiment
+// arm BigQuery fetches run at once, so a large cross-compare report doesn't pile unbounded
+// concurrent queries onto BigQuery. The cap is per report, not shared across reports, and
+// does not bound goroutines a fetch starts itself.
+const defaultMaxConcurrentQueries = 3
+
+func (c *componentReportGenerator) maxConcurrentQueries() int {
+	if c.MaxConcurrentQueries > 0 {
+		return c.MaxConcurrentQueries
+	}
+	return defaultMaxConcurrentQueries
+}
+
+func firstError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
 func (c *componentReportGenerator) getJobRunTestStatusFromBigQuery(ctx context.Context) (crtype.JobRunTestReportStatus, []error) {
-	allJobVariants, errs := GetJobVariantsFromBigQuery(ctx, c.client, c.gcsBucket)
+	var allJobVariants crtype.JobVariants
+	var errs []error
+	retry.Do(ctx, c.retryOptions(), isTransientBigQueryError(ctx), func(ctx context.Context) error {
+		allJobVariants, errs = GetJobVariantsFromBigQuery(ctx, c.client, c.gcsBucket)
+		return firstError(errs)
+	})
 	if len(errs) > 0 {
 		logrus.Errorf("failed to get variants from bigquery")
 		return crtype.JobRunTestReportStatus{}, errs
 	}
-	var baseStatus, baseOverrideStatus, sampleStatus map[string][]crtype.JobRunTestStatusRow
-	var baseErrs, baseOverrideErrs, sampleErrs []error
-	wg := sync.WaitGroup{}
 
+	type fetchResult = map[string][]crtype.JobRunTestStatusRow
+
+	jobs := []concurrency.Job[fetchResult]{
+		{
+			ID: "base",
+			Fn: func(ctx context.Context) (fetchResult, error) {
+				status, errs := c.getBaseJobRunTestStatus(ctx, allJobVariants, c.BaseRelease.Release, c.BaseRelease.Start, c.BaseRelease.End)
+				return status, firstError(errs)
+			},
+		},
+		{
+			ID: "sample",
+			Fn: func(ctx context.Context) (fetchResult, error) {
+				status, errs := c.getSampleJobRunTestStatus(ctx, allJobVariants)
+				return status, firstError(errs)
+			},
+		},
+	}
 	if c.BaseOverrideRelease.Release != "" && c.BaseOverrideRelease.Release != c.BaseRelease.Release {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			select {
-			case <-ctx.Done():
-				logrus.Infof("Context canceled while fetching base job run test status")
-				return
-			default:
-				baseOverrideStatus, baseOverrideErrs = c.getBaseJobRunTestStatus(ctx, allJobVariants, c.BaseOverrideRelease.Release, c.BaseOverrideRelease.Start, c.BaseOverrideRelease.End)
-			}
-		}()
+		jobs = append(jobs, concurrency.Job[fetchResult]{
+			ID: "baseOverride",
+			Fn: func(ctx context.Context) (fetchResult, error) {
+				status, errs := c.getBaseJobRunTestStatus(ctx, allJobVariants, c.BaseOverrideRelease.Release, c.BaseOverrideRelease.Start, c.BaseOverrideRelease.End)
+				return status, firstError(errs)
+			},
+		})
+	}
+	// ExperimentArms are fetched the same way as "base": by Release/Start/End, no other
+	// plumbing needed. The "extra:" prefix keeps their job IDs out of the "base"/"sample"/
+	// "baseOverride" namespace above; it's stripped back off below.
+	for _, arm := range c.ExperimentArms {
+		arm := arm
+		jobs = append(jobs, concurrency.Job[fetchResult]{
+			ID: "extra:" + arm.Name,
+			Fn: func(ctx context.Context) (fetchResult, error) {
+				status, errs := c.getBaseJobRunTestStatus(ctx, allJobVariants, arm.Release, arm.Start, arm.End)
+				return status, firstError(errs)
+			},
+		})
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		select {
-		case <-ctx.Done():
-			logrus.Infof("Context canceled while fetching base job run test status")
-			return
-		default:
-			baseStatus, baseErrs = c.getBaseJobRunTestStatus(ctx, allJobVariants, c.BaseRelease.Release, c.BaseRelease.Start, c.BaseRelease.End)
-		}
+	results, err := concurrency.ForEachJob(ctx, c.maxConcurrentQueries(), jobs)
+	if err != nil {
+		return crtype.JobRunTestReportStatus{}, []error{err}
+	}
 
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		select {
-		case <-ctx.Done():
-			logrus.Infof("Context canceled while fetching sample job run test status")
-			return
-		default:
-			sampleStatus, sampleErrs = c.getSampleJobRunTestStatus(ctx, allJobVariants)
+	var extraArmStatus map[string]map[string][]crtype.JobRunTestStatusRow
+	if len(c.ExperimentArms) > 0 {
+		extraArmStatus = make(map[string]map[string][]crtype.JobRunTestStatusRow, len(c.ExperimentArms))
+		for _, arm := range c.ExperimentArms {
+			extraArmStatus[arm.Name] = results["extra:"+arm.Name]
 		}
-
-	}()
-	wg.Wait()
-	if len(baseErrs) != 0 || len(baseOverrideErrs) != 0 || len(sampleErrs) != 0 {
-		errs = append(errs, baseErrs...)
-		errs = append(errs, baseOverrideErrs...)
-		errs = append(errs, sampleErrs...)
 	}
 
-	return crtype.JobRunTestReportStatus{BaseStatus: baseStatus, BaseOverrideStatus: baseOverrideStatus, SampleStatus: sampleStatus}, errs
+	return crtype.JobRunTestReportStatus{
+		BaseStatus:         results["base"],
+		BaseOverrideStatus: results["baseOverride"],
+		SampleStatus:       results["sample"],
+		ExtraArmStatus:     extraArmStatus,
+	}, nil
 }
 
 // internalGenerateTestDetailsReport handles the report generation for the lowest level test report including
@@ -267,6 +323,9 @@ func (c *componentReportGenerator) internalGenerateTestDetailsReport(ctx context
 		resolvedIssueCompensation, _ = c.triagedIncidentsFor(ctx, result.ReportTestIdentification)
 	}
 
+	sigTest := selectSignificanceTest(c.RequestAdvancedOptions.SignificanceTestBackend)
+	result.SignificanceTestUsed = sigTest.Name()
+
 	var totalBaseFailure, totalBaseSuccess, totalBaseFlake, totalSampleFailure, totalSampleSuccess, totalSampleFlake int
 	var perJobBaseFailure, perJobBaseSuccess, perJobBaseFlake, perJobSampleFailure, perJobSampleSuccess, perJobSampleFlake int
 
@@ -327,11 +386,9 @@ func (c *componentReportGenerator) internalGenerateTestDetailsReport(ctx context
 			perceivedSampleSuccess = perJobSampleSuccess
 			perceivedBaseSuccess = perJobBaseSuccess
 		}
-		_, _, r, _ := fet.FisherExactTest(perceivedSampleFailure,
-			perceivedSampleSuccess,
-			perceivedBaseFailure,
-			perceivedBaseSuccess)
-		jobStats.Significant = r < 1-float64(c.Confidence)/100
+		pValue, significant, _ := sigTest.Compare(perceivedSampleSuccess, perceivedSampleFailure, perceivedBaseSuccess, perceivedBaseFailure, float64(c.Confidence))
+		jobStats.Significant = significant
+		jobStats.PValue = pValue
 
 		result.JobStats = append(result.JobStats, jobStats)
 
@@ -366,11 +423,10 @@ func (c *componentReportGenerator) internalGenerateTestDetailsReport(ctx context
 			perceivedSampleFailure = perJobSampleFailure + perJobSampleFlake
 			perceivedSampleSuccess = perJobSampleSuccess
 		}
-		_, _, r, _ := fet.FisherExactTest(perceivedSampleFailure,
-			perceivedSampleSuccess,
-			0,
-			0)
-		jobStats.Significant = r < 1-float64(c.Confidence)/100
+		pValue, significant, _ := sigTest.Compare(perceivedSampleSuccess, perceivedSampleFailure, 0, 0, float64(c.Confidence))
+		// jobStats was already appended above, so record the result on the stored copy.
+		result.JobStats[len(result.JobStats)-1].Significant = significant
+		result.JobStats[len(result.JobStats)-1].PValue = pValue
 
 		totalSampleFailure += perJobSampleFailure
 		totalSampleSuccess += perJobSampleSuccess
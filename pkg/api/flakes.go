@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/flakes"
+)
+
+// ListFlakyTestsFromDB handles GET /api/tests/flaky, returning the current flaky-test
+// detections for a release (open by default; pass status=closed for the closed list).
+func ListFlakyTestsFromDB(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
+	status := req.URL.Query().Get("status")
+	if status == "" {
+		status = flakes.StatusOpen
+	}
+
+	var results []flakes.FlakyTest
+	res := dbc.DB.Where("release = ? AND status = ?", release, status).Order("flake_rate desc").Find(&results)
+	if res.Error != nil {
+		log.WithError(res.Error).Error("error listing flaky tests")
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error listing flaky tests:" + res.Error.Error()})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, results)
+}
+
+// ForceCloseFlakyTest handles POST /api/tests/flaky/close, manually closing a flaky-test
+// detection ahead of its normal days-before-auto-close window (e.g. once a fix has merged).
+func ForceCloseFlakyTest(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "test is required"})
+		return
+	}
+
+	res := dbc.DB.Model(&flakes.FlakyTest{}).
+		Where("release = ? AND test_name = ?", release, testName).
+		Update("status", flakes.StatusClosed)
+	if res.Error != nil {
+		log.WithError(res.Error).Error("error force-closing flaky test")
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error closing flaky test:" + res.Error.Error()})
+		return
+	}
+	if res.RowsAffected == 0 {
+		RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{"code": http.StatusNotFound, "message": "No flaky test detection found for " + testName})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, map[string]interface{}{"message": "closed"})
+}
+
+// ReopenFlakyTest handles POST /api/tests/flaky/reopen, undoing a force-close (or an
+// auto-close the on-call decides was premature).
+func ReopenFlakyTest(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "test is required"})
+		return
+	}
+
+	res := dbc.DB.Model(&flakes.FlakyTest{}).
+		Where("release = ? AND test_name = ?", release, testName).
+		Update("status", flakes.StatusOpen)
+	if res.Error != nil {
+		log.WithError(res.Error).Error("error reopening flaky test")
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error reopening flaky test:" + res.Error.Error()})
+		return
+	}
+	if res.RowsAffected == 0 {
+		RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{"code": http.StatusNotFound, "message": "No flaky test detection found for " + testName})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, map[string]interface{}{"message": "reopened"})
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// releaseHandlerFunc is the signature shared by the handlers in this package that take
+// their release from the caller rather than parsing it themselves (PrintTestsJSONFromDB,
+// ListFlakyTestsFromDB, and friends).
+type releaseHandlerFunc func(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB)
+
+// withRelease adapts a releaseHandlerFunc to a plain http.HandlerFunc by pulling the
+// release out of the "release" query parameter, the same place every handler in this
+// package already looks for its other parameters.
+func withRelease(dbc *db.DB, h releaseHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		h(req.URL.Query().Get("release"), w, req, dbc)
+	}
+}
+
+// RegisterTestsRoutes wires this package's newer test-reporting handlers to their HTTP
+// routes. It's meant to be called alongside whatever registers the existing /api/tests
+// route, not as a replacement for it.
+func RegisterTestsRoutes(mux *http.ServeMux, dbc *db.DB) {
+	mux.HandleFunc("/api/tests/timeseries", withRelease(dbc, PrintTestTimeSeriesFromDB))
+	mux.HandleFunc("/api/tests/flaky", withRelease(dbc, ListFlakyTestsFromDB))
+	mux.HandleFunc("/api/tests/flaky/close", withRelease(dbc, ForceCloseFlakyTest))
+	mux.HandleFunc("/api/tests/flaky/reopen", withRelease(dbc, ReopenFlakyTest))
+}
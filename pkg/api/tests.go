@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"reflect"
 	gosort "sort"
 	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -68,8 +70,288 @@ func GetTestDurationsFromDB(dbc *db.DB, release, test string, filters *filter.Fi
 	return query.TestDurations(dbc, release, test, includedVariants, excludedVariants)
 }
 
+// testFieldColumns maps the json tag of each apitype.Test field to its underlying
+// SQL column/alias name, as produced by query.QueryTestSummer/QueryTestSummarizer. It's
+// built once from struct tags so the allowlist can't drift out of sync with apitype.Test.
+var testFieldColumns = buildTestFieldColumns()
+
+func buildTestFieldColumns() map[string]string {
+	columns := map[string]string{}
+	t := reflect.TypeOf(apitype.Test{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "" || jsonTag == "-" {
+			continue
+		}
+		name := strings.Split(jsonTag, ",")[0]
+		if name == "" {
+			continue
+		}
+		columns[name] = name
+	}
+	return columns
+}
+
+// splitTopLevelClauses splits a comma-separated SQL select fragment on commas that are not
+// nested inside parentheses, so a clause like "ROUND(STDDEV(x), 2) as y" -- common among
+// the delta/average/stddev columns QueryTestSummer/QueryTestSummarizer select -- survives
+// as one clause instead of being cut in two at the argument-list comma.
+func splitTopLevelClauses(fragment string) []string {
+	var clauses []string
+	depth := 0
+	start := 0
+	for i, r := range fragment {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				clauses = append(clauses, fragment[start:i])
+				start = i + 1
+			}
+		}
+	}
+	clauses = append(clauses, fragment[start:])
+	return clauses
+}
+
+// filterSummerFragment narrows a SQL select fragment (as produced by
+// query.QueryTestSummer/QueryTestSummarizer) down to the clauses whose " as <alias>"
+// alias is in allowed. It's a best-effort textual filter, not a SQL parser -- clauses are
+// split on top-level commas only (see splitTopLevelClauses), so a nested argument list
+// can't fracture a clause, and any clause we can't confidently match an alias for is kept
+// rather than silently dropped.
+func filterSummerFragment(fragment string, allowed map[string]bool) string {
+	if len(allowed) == 0 {
+		return fragment
+	}
+
+	var kept []string
+	for _, clause := range splitTopLevelClauses(fragment) {
+		trimmed := strings.TrimSpace(clause)
+		if trimmed == "" {
+			continue
+		}
+		idx := strings.LastIndex(strings.ToLower(trimmed), " as ")
+		if idx == -1 {
+			kept = append(kept, clause)
+			continue
+		}
+		alias := strings.TrimSpace(trimmed[idx+4:])
+		if allowed[alias] {
+			kept = append(kept, clause)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// aggregateByAllowlist maps the public aggregateBy dimension names to the columns they
+// group by. There is deliberately no per-key "variant:<key>" entry: prow_job_run_tests
+// stores a job's variants as a single flat "variants" column (see GetTestOutputsFromDB's
+// includedVariants/excludedVariants filtering), not one column per variant key, so there is
+// no "platform"/"network"/etc. column for a key to resolve to. "variant" (no key) groups by
+// that whole column instead.
+var aggregateByAllowlist = map[string]string{
+	"component": "jira_component",
+	"suite":     "suite_name",
+	"variant":   "variants",
+}
+
+// parseAggregateBy validates and translates a comma-separated "aggregateBy" query param
+// (e.g. "component", "suite", "variant", or a combination) into the underlying SQL columns
+// to GROUP BY. "variant:<key>" is rejected rather than guessed at: this schema has no column
+// per variant key for param.Cleanse(key) to resolve to, only the single flat "variants"
+// column "variant" already groups by.
+func parseAggregateBy(req *http.Request) ([]string, error) {
+	raw := req.URL.Query().Get("aggregateBy")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var cols []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, "variant:") {
+			key := param.Cleanse(strings.TrimPrefix(part, "variant:"))
+			return nil, fmt.Errorf("aggregateBy variant:%s is not supported: tests are only grouped by the whole variants column, use aggregateBy=variant instead", key)
+		}
+		col, ok := aggregateByAllowlist[part]
+		if !ok {
+			return nil, fmt.Errorf("unknown aggregateBy dimension %q", part)
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// parseFields validates a comma-separated "fields" query param against the allowlist
+// derived from apitype.Test's json tags, returning the requested columns (deduped, "id"
+// and "name" always included since they identify the row). An empty fields param means
+// "return everything", matching today's behavior.
+func parseFields(req *http.Request) ([]string, error) {
+	raw := req.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	seen := map[string]bool{"id": true, "name": true}
+	fields := []string{"id", "name"}
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if _, ok := testFieldColumns[f]; !ok {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+		if !seen[f] {
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
 type testsAPIResult []apitype.Test
 
+// sortFieldRegressionScore ranks tests by how many standard deviations the current pass
+// rate has fallen below the trailing working average (a Welch-style z-score), rather than
+// by a raw apitype.Test column, so it's handled as a special case rather than through
+// filter.Compare.
+const sortFieldRegressionScore = "regression_score"
+
+// testFieldFloat reads the apitype.Test field tagged with the given json name as a float64,
+// regardless of whether it's backed by a float or an int column. Looking it up by tag
+// (rather than hard-coding the Go field name) keeps this resilient to apitype.Test being a
+// type we don't own here.
+func testFieldFloat(t apitype.Test, jsonTag string) (float64, bool) {
+	v := reflect.ValueOf(t)
+	ft := v.Type()
+	for i := 0; i < ft.NumField(); i++ {
+		tag := strings.Split(ft.Field(i).Tag.Get("json"), ",")[0]
+		if tag != jsonTag {
+			continue
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return fv.Float(), true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(fv.Int()), true
+		}
+	}
+	return 0, false
+}
+
+// regressionScore computes a Welch-style z-score for how far below the trailing working
+// average a test's current pass percentage has fallen:
+// z = (current - workingAverage) / sqrt(stddev^2/currentRuns + stddev^2/N)
+// where N is the run count the working average was computed over (previous_runs).
+func regressionScore(t apitype.Test) float64 {
+	current, ok := testFieldFloat(t, "current_pass_percentage")
+	if !ok {
+		return 0
+	}
+	workingAverage, ok := testFieldFloat(t, "working_average")
+	if !ok {
+		return 0
+	}
+	stddev, ok := testFieldFloat(t, "working_standard_deviation")
+	if !ok || stddev == 0 {
+		return 0
+	}
+	currentRuns, ok := testFieldFloat(t, "current_runs")
+	if !ok || currentRuns == 0 {
+		return 0
+	}
+	n, ok := testFieldFloat(t, "previous_runs")
+	if !ok || n == 0 {
+		n = currentRuns
+	}
+
+	variance := stddev*stddev/currentRuns + stddev*stddev/n
+	if variance <= 0 {
+		return 0
+	}
+	return (current - workingAverage) / math.Sqrt(variance)
+}
+
+// wilsonInterval returns the 95% Wilson score confidence interval, in percentage points,
+// for a pass percentage observed over n runs.
+func wilsonInterval(passPercentage float64, n int) (low, high float64) {
+	if n == 0 {
+		return 0, 0
+	}
+	const z = 1.96 // 95% confidence
+	p := passPercentage / 100
+	nf := float64(n)
+
+	denominator := 1 + z*z/nf
+	center := p + z*z/(2*nf)
+	margin := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf))
+
+	low = (center - margin) / denominator * 100
+	high = (center + margin) / denominator * 100
+	if low < 0 {
+		low = 0
+	}
+	if high > 100 {
+		high = 100
+	}
+	return low, high
+}
+
+// testWithRegressionScore decorates an apitype.Test with the regression_score and Wilson
+// confidence interval bounds computed by sortField=regression_score, without requiring
+// changes to apitype.Test itself.
+type testWithRegressionScore struct {
+	apitype.Test
+	RegressionScore        float64 `json:"regression_score"`
+	ConfidenceIntervalLow  float64 `json:"confidence_interval_low"`
+	ConfidenceIntervalHigh float64 `json:"confidence_interval_high"`
+}
+
+func decorateWithRegressionScore(tests testsAPIResult) []testWithRegressionScore {
+	decorated := make([]testWithRegressionScore, len(tests))
+	for i, t := range tests {
+		currentRuns, _ := testFieldFloat(t, "current_runs")
+		currentPassPct, _ := testFieldFloat(t, "current_pass_percentage")
+		low, high := wilsonInterval(currentPassPct, int(currentRuns))
+		decorated[i] = testWithRegressionScore{
+			Test:                   t,
+			RegressionScore:        regressionScore(t),
+			ConfidenceIntervalLow:  low,
+			ConfidenceIntervalHigh: high,
+		}
+	}
+	return decorated
+}
+
+// minRuns filters out tests with fewer than minRuns current_runs, so low-volume tests
+// don't dominate sortField=regression_score results. A minRuns of 0 (the default) is a
+// no-op.
+func (tests testsAPIResult) minRuns(req *http.Request) testsAPIResult {
+	minRuns, _ := strconv.Atoi(req.URL.Query().Get("min_runs"))
+	if minRuns == 0 {
+		return tests
+	}
+
+	filtered := make(testsAPIResult, 0, len(tests))
+	for _, t := range tests {
+		currentRuns, ok := testFieldFloat(t, "current_runs")
+		if !ok || currentRuns >= float64(minRuns) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
 func (tests testsAPIResult) sort(req *http.Request) testsAPIResult {
 	sortField := param.SafeRead(req, "sortField")
 	sort := param.SafeRead(req, "sort")
@@ -83,6 +365,12 @@ func (tests testsAPIResult) sort(req *http.Request) testsAPIResult {
 	}
 
 	gosort.Slice(tests, func(i, j int) bool {
+		if sortField == sortFieldRegressionScore {
+			if sort == "asc" {
+				return regressionScore(tests[i]) < regressionScore(tests[j])
+			}
+			return regressionScore(tests[j]) < regressionScore(tests[i])
+		}
 		if sort == "asc" {
 			return filter.Compare(tests[i], tests[j], sortField)
 		}
@@ -101,6 +389,12 @@ func (tests testsAPIResult) limit(req *http.Request) testsAPIResult {
 	return tests[:limit]
 }
 
+// PrintTestsJSONFromDB handles GET /api/tests, returning per-test (or, with aggregateBy,
+// per-group) pass/fail summaries for a release. Among its query params, aggregateBy accepts
+// "component", "suite", "variant", or a comma-separated combination -- but NOT the per-key
+// "variant:<key>" form (e.g. "variant:platform"): the schema groups by the whole flat
+// "variants" column and has no column per variant key to resolve a key against. See
+// parseAggregateBy and aggregateByAllowlist for why.
 func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
 	var fil *filter.Filter
 
@@ -136,17 +430,56 @@ func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	testsResult, overall, err := BuildTestsResults(dbc, release, period, collapse, includeOverall, fil)
+	fields, err := parseFields(req)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	aggregateBy, err := parseAggregateBy(req)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	window, hasWindow, err := parseWindow(req)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	// regression_score depends on the working_average/working_standard_deviation columns,
+	// which are only computed by the per-NURP+ matview query (collapse=false, no
+	// aggregateBy, no custom window). Reject the combination instead of silently sorting
+	// by an all-zero score.
+	if param.SafeRead(req, "sortField") == sortFieldRegressionScore && (collapse || len(aggregateBy) > 0 || hasWindow) {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "sortField=regression_score requires collapse=false, no aggregateBy, and no custom window"})
+		return
+	}
+
+	var testsResult testsAPIResult
+	var overall *apitype.Test
+	if hasWindow {
+		useCache := req.URL.Query().Get("cache") != "false"
+		testsResult, overall, err = buildTestsResultsForWindowCached(dbc, release, window, collapse, includeOverall, fil, fields, aggregateBy, useCache)
+	} else {
+		testsResult, overall, err = BuildTestsResults(dbc, release, period, collapse, includeOverall, fil, fields, aggregateBy)
+	}
 	if err != nil {
 		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
 		return
 	}
 
-	testsResult = testsResult.sort(req).limit(req)
+	testsResult = testsResult.minRuns(req).sort(req).limit(req)
 	if overall != nil {
 		testsResult = append([]apitype.Test{*overall}, testsResult...)
 	}
 
+	if param.SafeRead(req, "sortField") == sortFieldRegressionScore {
+		RespondWithJSON(http.StatusOK, w, decorateWithRegressionScore(testsResult))
+		return
+	}
+
 	RespondWithJSON(http.StatusOK, w, testsResult)
 }
 
@@ -161,7 +494,7 @@ func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
 		},
 	}
 
-	results, _, err := BuildTestsResults(dbc, release, "default", true, false, &f)
+	results, _, err := BuildTestsResults(dbc, release, "default", true, false, &f, nil, nil)
 	if err != nil {
 		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building test report:" + err.Error()})
 		return
@@ -173,7 +506,13 @@ func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
 	}
 }
 
-func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOverall bool, fil *filter.Filter) (testsAPIResult, *apitype.Test, error) { //lint:ignore
+// BuildTestsResults builds the tests API result set. When fields is non-empty, the
+// returned apitype.Test rows are projected down to just those columns (plus id/name) --
+// the allowlist is validated by the caller via parseFields before we ever see it here.
+// When aggregateBy is non-empty, rows are grouped by those dimensions (e.g. jira_component,
+// suite_name, or the whole variants column) instead of by individual test, and collapse/the per-NURP+
+// breakdown are ignored -- aggregateBy is its own grouping mode.
+func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOverall bool, fil *filter.Filter, fields, aggregateBy []string) (testsAPIResult, *apitype.Test, error) { //lint:ignore
 	now := time.Now()
 
 	// Test results are generated by using two subqueries, which need to be filtered separately. Once during
@@ -193,10 +532,27 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 		Table(table).
 		Where("release = ?", release)
 
+	var fieldAllowlist map[string]bool
+	if len(fields) > 0 {
+		fieldAllowlist = make(map[string]bool, len(fields))
+		for _, f := range fields {
+			fieldAllowlist[f] = true
+		}
+	}
+
 	// Collapse groups the test results together -- otherwise we return the test results per-variant combo (NURP+)
+	// aggregateBy is a third grouping mode: group by arbitrary dimensions (component, suite,
+	// variants) instead of by individual test name.
 	variantSelect := ""
-	if collapse {
-		rawQuery = rawQuery.Select(`name,jira_component,jira_component_id,` + query.QueryTestSummer).Group("name,jira_component,jira_component_id")
+	idCols := "name, jira_component, jira_component_id"
+	groupCols := "name,jira_component,jira_component_id"
+	if len(aggregateBy) > 0 {
+		groupCols = strings.Join(aggregateBy, ",")
+		idCols = fmt.Sprintf(`CONCAT_WS(' / ', %s::text) as name, %s`, strings.Join(aggregateBy, "::text, "), groupCols)
+	}
+
+	if len(aggregateBy) > 0 || collapse {
+		rawQuery = rawQuery.Select(idCols + "," + filterSummerFragment(query.QueryTestSummer, fieldAllowlist)).Group(groupCols)
 	} else {
 		rawQuery = query.TestsByNURPAndStandardDeviation(dbc, release, table)
 		variantSelect = "suite_name, variants," +
@@ -213,13 +569,16 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 	testReports := make([]apitype.Test, 0)
 	// FIXME: Add test id to matview, for now generate with ROW_NUMBER OVER
 	processedResults := dbc.DB.Table("(?) as results", rawQuery).
-		Select(`ROW_NUMBER() OVER() as id, name, jira_component, jira_component_id,` + variantSelect + query.QueryTestSummarizer).
+		Select(`ROW_NUMBER() OVER() as id, ` + idCols + `,` + variantSelect + filterSummerFragment(query.QueryTestSummarizer, fieldAllowlist)).
 		Where("current_runs > 0 or previous_runs > 0")
 
 	finalResults := dbc.DB.Table("(?) as final_results", processedResults)
 	if processedFilter != nil {
 		finalResults = processedFilter.ToSQL(finalResults, apitype.Test{})
 	}
+	if len(fields) > 0 {
+		finalResults = finalResults.Select(strings.Join(fields, ","))
+	}
 
 	frr := finalResults.Scan(&testReports)
 	if frr.Error != nil {
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"math"
+	"testing"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		name           string
+		passPercentage float64
+		n              int
+		wantLow        float64
+		wantHigh       float64
+	}{
+		{
+			name:           "zero runs returns zero interval",
+			passPercentage: 100,
+			n:              0,
+			wantLow:        0,
+			wantHigh:       0,
+		},
+		{
+			name:           "100% over many runs is a narrow interval near 100",
+			passPercentage: 100,
+			n:              1000,
+			wantLow:        99.6,
+			wantHigh:       100,
+		},
+		{
+			name:           "50% over a handful of runs is a wide interval around 50",
+			passPercentage: 50,
+			n:              10,
+			wantLow:        23,
+			wantHigh:       77,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low, high := wilsonInterval(tt.passPercentage, tt.n)
+			if low < 0 || high > 100 || low > high {
+				t.Fatalf("wilsonInterval(%v, %v) = (%v, %v), want a valid interval within [0, 100]", tt.passPercentage, tt.n, low, high)
+			}
+			if math.Abs(low-tt.wantLow) > 1 {
+				t.Errorf("wilsonInterval(%v, %v) low = %v, want ~%v", tt.passPercentage, tt.n, low, tt.wantLow)
+			}
+			if math.Abs(high-tt.wantHigh) > 1 {
+				t.Errorf("wilsonInterval(%v, %v) high = %v, want ~%v", tt.passPercentage, tt.n, high, tt.wantHigh)
+			}
+		})
+	}
+}
+
+func TestFilterSummerFragment(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		allowed  map[string]bool
+		want     string
+	}{
+		{
+			name:     "nil allowlist returns fragment unfiltered",
+			fragment: "current_runs, current_successes",
+			allowed:  nil,
+			want:     "current_runs, current_successes",
+		},
+		{
+			name: "clause with a nested comma survives intact when its alias is allowed",
+			// ROUND(..., 2)'s argument-list comma must not be mistaken for a clause
+			// separator, or this stddev column (same family query.QueryTestSummarizer
+			// selects for BuildTestsResults' NURP+ path) would be cut mid-expression.
+			fragment: "working_average," +
+				"ROUND(STDDEV(current_pass_percentage)::numeric, 2) as working_standard_deviation," +
+				"passing_average",
+			// working_average and passing_average carry no " as " alias, so they are kept.
+			allowed: map[string]bool{"working_standard_deviation": true},
+			want: "working_average," +
+				"ROUND(STDDEV(current_pass_percentage)::numeric, 2) as working_standard_deviation," +
+				"passing_average",
+		},
+		{
+			name: "clause with a nested comma is dropped intact when its alias isn't allowed",
+			fragment: "ROUND(STDDEV(current_pass_percentage)::numeric, 2) as working_standard_deviation," +
+				"passing_average as passing_average",
+			allowed: map[string]bool{"passing_average": true},
+			want:    "passing_average as passing_average",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterSummerFragment(tt.fragment, tt.allowed)
+			if got != tt.want {
+				t.Errorf("filterSummerFragment(%q, %v) = %q, want %q", tt.fragment, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegressionScore(t *testing.T) {
+	tests := []struct {
+		name string
+		test apitype.Test
+		want float64
+	}{
+		{
+			name: "missing working average fields returns zero rather than a bogus score",
+			test: apitype.Test{CurrentPassPercentage: 90, CurrentRuns: 20},
+			want: 0,
+		},
+		{
+			name: "current pass rate matching the working average scores zero",
+			test: apitype.Test{
+				CurrentPassPercentage:    95,
+				CurrentRuns:              20,
+				WorkingAverage:           95,
+				WorkingStandardDeviation: 2,
+				PreviousRuns:             100,
+			},
+			want: 0,
+		},
+		{
+			name: "a drop below the working average scores negative",
+			test: apitype.Test{
+				CurrentPassPercentage:    80,
+				CurrentRuns:              20,
+				WorkingAverage:           95,
+				WorkingStandardDeviation: 2,
+				PreviousRuns:             100,
+			},
+			want: -1, // sign only; the magnitude is not asserted
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := regressionScore(tt.test)
+			if tt.want == 0 && got != 0 {
+				t.Fatalf("regressionScore() = %v, want 0", got)
+			}
+			if tt.want < 0 && got >= 0 {
+				t.Fatalf("regressionScore() = %v, want a negative score", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+// TestTimeSeriesPoint is a single bucket in a test's pass/fail/flake time series, along
+// with the running cumulative totals up to and including that bucket.
+type TestTimeSeriesPoint struct {
+	Time        time.Time `json:"time"`
+	Passes      int       `json:"passes"`
+	Failures    int       `json:"failures"`
+	Flakes      int       `json:"flakes"`
+	CumPasses   int       `json:"cum_passes"`
+	CumFailures int       `json:"cum_failures"`
+}
+
+// validTimeSeriesBuckets are the only bucket widths we'll truncate on; anything else is
+// a 400, same as PrintTestsJSONFromDB's "period" validation.
+var validTimeSeriesBuckets = map[string]string{
+	"1h": "hour",
+	"1d": "day",
+}
+
+// PrintTestTimeSeriesFromDB handles GET /api/tests/timeseries, returning per-bucket
+// pass/fail/flake counts and cumulative sums for a test (or filter set) over a window.
+func PrintTestTimeSeriesFromDB(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
+	var fil *filter.Filter
+	queryFilter := req.URL.Query().Get("filter")
+	if queryFilter != "" {
+		fil = &filter.Filter{}
+		if err := json.Unmarshal([]byte(queryFilter), fil); err != nil {
+			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
+			return
+		}
+	}
+
+	test := req.URL.Query().Get("test")
+	if test == "" && fil == nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "test or filter is required"})
+		return
+	}
+
+	bucket := req.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = "1d"
+	}
+	if _, ok := validTimeSeriesBuckets[bucket]; !ok {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Unknown bucket, must be 1h or 1d"})
+		return
+	}
+
+	from, to, err := parseTimeSeriesWindow(req)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	points, err := BuildTestTimeSeries(dbc, release, test, bucket, from, to, fil)
+	if err != nil {
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building test time series:" + err.Error()})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, points)
+}
+
+func parseTimeSeriesWindow(req *http.Request) (time.Time, time.Time, error) {
+	to := time.Now()
+	from := to.Add(-14 * 24 * time.Hour)
+
+	if v := req.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+		}
+		to = parsed
+	}
+
+	if v := req.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+		}
+		from = parsed
+	} else if v := req.URL.Query().Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid days: %w", err)
+		}
+		from = to.Add(-time.Duration(days) * 24 * time.Hour)
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+// BuildTestTimeSeries buckets prow_job_runs/test results for a test (or filter set) by
+// date_trunc(bucket, timestamp) and layers a running cumulative sum over the bucketed
+// passes/failures using SUM() OVER (ORDER BY bucket), so historical windows work without
+// depending on the 7d/2d matviews.
+func BuildTestTimeSeries(dbc *db.DB, release, test, bucket string, from, to time.Time, fil *filter.Filter) ([]TestTimeSeriesPoint, error) {
+	now := time.Now()
+
+	truncUnit := validTimeSeriesBuckets[bucket]
+
+	// Resolve the matching prow_job_run_tests rows against only prow_job_run_tests/tests,
+	// before prow_jobs ever enters the query. prow_jobs has its own name column, so if
+	// fil.ToSQL (or the plain test-name predicate) isn't careful to qualify "name", doing
+	// this join first would risk the same ambiguous-column-reference error that a8aadd0
+	// fixed in tests_window.go -- resolving matches before the join sidesteps it entirely.
+	matched := dbc.DB.Table("prow_job_run_tests").
+		Select("prow_job_run_tests.id, prow_job_run_tests.timestamp, prow_job_run_tests.status, prow_job_run_tests.prow_job_run_id").
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Where("prow_job_run_tests.timestamp BETWEEN ? AND ?", from, to)
+
+	if test != "" {
+		matched = matched.Where("tests.name = ?", test)
+	}
+	if fil != nil {
+		matched = fil.ToSQL(matched, apitype.Test{})
+	}
+
+	bucketed := dbc.DB.Table("(?) as matched", matched).
+		Select(fmt.Sprintf(`date_trunc('%s', matched.timestamp) as bucket,
+			SUM(CASE WHEN matched.status = 1 THEN 1 ELSE 0 END) as passes,
+			SUM(CASE WHEN matched.status = 0 THEN 1 ELSE 0 END) as failures,
+			SUM(CASE WHEN matched.status = 2 THEN 1 ELSE 0 END) as flakes`, truncUnit)).
+		Joins("JOIN prow_job_runs ON prow_job_runs.id = matched.prow_job_run_id").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_jobs.release = ?", release).
+		Group("bucket")
+
+	cumulative := dbc.DB.Table("(?) as bucketed", bucketed).
+		Select(`bucket as time, passes, failures, flakes,
+			SUM(passes) OVER (ORDER BY bucket) as cum_passes,
+			SUM(failures) OVER (ORDER BY bucket) as cum_failures`).
+		Order("bucket")
+
+	var points []TestTimeSeriesPoint
+	res := cumulative.Scan(&points)
+	if res.Error != nil {
+		log.WithError(res.Error).Error("error querying test time series")
+		return nil, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"points":  len(points),
+	}).Debug("BuildTestTimeSeries completed")
+
+	return points, nil
+}
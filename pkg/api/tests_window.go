@@ -0,0 +1,226 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+// testsWindowCacheTTL and testsWindowCacheMaxEntries bound buildTestsResultsForWindowCached's
+// in-process cache. A custom window is rebuilt from raw tables on every miss, so a short TTL
+// (rather than "until next deploy") keeps results reasonably fresh without hammering the DB
+// when a dashboard is polling the same window from multiple browsers.
+const (
+	testsWindowCacheTTL        = 5 * time.Minute
+	testsWindowCacheMaxEntries = 256
+)
+
+var testsWindowCache = newTTLLRUCache(testsWindowCacheMaxEntries, testsWindowCacheTTL)
+
+type testsWindow struct {
+	start, end time.Time
+}
+
+// parseWindow looks for an explicit start/end or days query param requesting a custom,
+// matview-free time window. hasWindow is false (with a zero window) when none of these
+// params are present, meaning the caller should fall back to the 7d/2d matviews.
+func parseWindow(req *http.Request) (testsWindow, bool, error) {
+	startStr := req.URL.Query().Get("start")
+	endStr := req.URL.Query().Get("end")
+	daysStr := req.URL.Query().Get("days")
+
+	if startStr == "" && endStr == "" && daysStr == "" {
+		return testsWindow{}, false, nil
+	}
+
+	end := time.Now()
+	if endStr != "" {
+		parsed, err := time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			return testsWindow{}, false, fmt.Errorf("invalid end: %w", err)
+		}
+		end = parsed
+	}
+
+	var start time.Time
+	switch {
+	case startStr != "":
+		parsed, err := time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			return testsWindow{}, false, fmt.Errorf("invalid start: %w", err)
+		}
+		start = parsed
+	case daysStr != "":
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			return testsWindow{}, false, fmt.Errorf("invalid days: %s", daysStr)
+		}
+		start = end.Add(-time.Duration(days) * 24 * time.Hour)
+	default:
+		return testsWindow{}, false, fmt.Errorf("start/end requires one of start or days")
+	}
+
+	if !start.Before(end) {
+		return testsWindow{}, false, fmt.Errorf("start must be before end")
+	}
+
+	return testsWindow{start: start, end: end}, true, nil
+}
+
+type cachedTestsResult struct {
+	Tests   testsAPIResult
+	Overall *apitype.Test
+}
+
+// buildTestsResultsForWindowCached wraps BuildTestsResultsForWindow with an in-process
+// LRU+TTL cache keyed by (release, window, filter-hash, fields, aggregateBy). Pass
+// useCache=false (cache=false on the request) to force a rebuild and skip populating the
+// cache with it.
+func buildTestsResultsForWindowCached(dbc *db.DB, release string, window testsWindow, collapse, includeOverall bool, fil *filter.Filter, fields, aggregateBy []string, useCache bool) (testsAPIResult, *apitype.Test, error) {
+	key := testsWindowCacheKey(release, window, collapse, includeOverall, fil, fields, aggregateBy)
+
+	if useCache {
+		if cached, ok := testsWindowCache.get(key); ok {
+			log.WithField("key", key).Debug("tests window cache hit")
+			result := cached.(cachedTestsResult)
+			return result.Tests, result.Overall, nil
+		}
+		log.WithField("key", key).Debug("tests window cache miss")
+	}
+
+	tests, overall, err := BuildTestsResultsForWindow(dbc, release, window.start, window.end, collapse, includeOverall, fil, fields, aggregateBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if useCache {
+		testsWindowCache.set(key, cachedTestsResult{Tests: tests, Overall: overall})
+	}
+
+	return tests, overall, nil
+}
+
+func testsWindowCacheKey(release string, window testsWindow, collapse, includeOverall bool, fil *filter.Filter, fields, aggregateBy []string) string {
+	var filterHash string
+	if fil != nil {
+		if b, err := json.Marshal(fil); err == nil {
+			sum := sha256.Sum256(b)
+			filterHash = hex.EncodeToString(sum[:])
+		}
+	}
+
+	return strings.Join([]string{
+		release,
+		window.start.UTC().Format(time.RFC3339),
+		window.end.UTC().Format(time.RFC3339),
+		strconv.FormatBool(collapse),
+		strconv.FormatBool(includeOverall),
+		filterHash,
+		strings.Join(fields, ","),
+		strings.Join(aggregateBy, ","),
+	}, "|")
+}
+
+// BuildTestsResultsForWindow is the matview-free equivalent of BuildTestsResults: it
+// aggregates prow_job_run_tests directly over [start, end] instead of reading the 7d/2d
+// matviews, so arbitrary windows work without ops adding a new matview for every range.
+// previous_* columns aren't meaningful for an arbitrary window and are left zeroed.
+func BuildTestsResultsForWindow(dbc *db.DB, release string, start, end time.Time, collapse, includeOverall bool, fil *filter.Filter, fields, aggregateBy []string) (testsAPIResult, *apitype.Test, error) {
+	now := time.Now()
+
+	var rawFilter, processedFilter *filter.Filter
+	if fil != nil {
+		rawFilter, processedFilter = fil.Split([]string{"name", "variants"})
+	}
+
+	var fieldAllowlist map[string]bool
+	if len(fields) > 0 {
+		fieldAllowlist = make(map[string]bool, len(fields))
+		for _, f := range fields {
+			fieldAllowlist[f] = true
+		}
+	}
+
+	idCols := "tests.name as name, jira_component, jira_component_id"
+	groupCols := "tests.name,jira_component,jira_component_id"
+	if len(aggregateBy) > 0 {
+		groupCols = strings.Join(aggregateBy, ",")
+		idCols = fmt.Sprintf(`CONCAT_WS(' / ', %s::text) as name, %s`, strings.Join(aggregateBy, "::text, "), groupCols)
+	} else if !collapse {
+		idCols = "tests.name as name, jira_component, jira_component_id, variants"
+		groupCols = "tests.name,jira_component,jira_component_id,variants"
+	}
+
+	summer := filterSummerFragment(`
+		COUNT(*) as current_runs,
+		SUM(CASE WHEN prow_job_run_tests.status = 1 THEN 1 ELSE 0 END) as current_successes,
+		SUM(CASE WHEN prow_job_run_tests.status = 0 THEN 1 ELSE 0 END) as current_failures,
+		SUM(CASE WHEN prow_job_run_tests.status = 2 THEN 1 ELSE 0 END) as current_flakes,
+		SUM(CASE WHEN prow_job_run_tests.status = 1 THEN 1 ELSE 0 END) * 100.0 / COUNT(*) as current_pass_percentage`,
+		fieldAllowlist)
+
+	rawQuery := dbc.DB.Table("prow_job_run_tests").
+		Select(idCols+","+summer).
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_jobs.release = ?", release).
+		Where("prow_job_run_tests.timestamp BETWEEN ? AND ?", start, end).
+		Group(groupCols)
+
+	if rawFilter != nil {
+		rawQuery = rawFilter.ToSQL(rawQuery, apitype.Test{})
+	}
+
+	processedResults := dbc.DB.Table("(?) as results", rawQuery).
+		Select("ROW_NUMBER() OVER() as id, *").
+		Where("current_runs > 0")
+
+	finalResults := dbc.DB.Table("(?) as final_results", processedResults)
+	if processedFilter != nil {
+		finalResults = processedFilter.ToSQL(finalResults, apitype.Test{})
+	}
+	if len(fields) > 0 {
+		finalResults = finalResults.Select(strings.Join(fields, ","))
+	}
+
+	testReports := make([]apitype.Test, 0)
+	frr := finalResults.Scan(&testReports)
+	if frr.Error != nil {
+		log.WithError(frr.Error).Error("error querying windowed test reports")
+		return []apitype.Test{}, nil, frr.Error
+	}
+
+	var overallTest *apitype.Test
+	if includeOverall {
+		overallQuery := dbc.DB.Table("(?) as final_results", finalResults).
+			Select(`SUM(current_runs) as current_runs,
+				SUM(current_successes) as current_successes,
+				SUM(current_failures) as current_failures,
+				SUM(current_flakes) as current_flakes,
+				SUM(current_successes) * 100.0 / NULLIF(SUM(current_runs), 0) as current_pass_percentage`)
+		overallTest = &apitype.Test{ID: math.MaxInt32, Name: "Overall"}
+		overallQuery.Scan(overallTest)
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"reports": len(testReports),
+		"start":   start,
+		"end":     end,
+	}).Debug("BuildTestsResultsForWindow completed")
+
+	return testReports, overallTest, nil
+}
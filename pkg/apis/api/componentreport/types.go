@@ -0,0 +1,268 @@
+// Package componentreport holds the request/response types shared between the component
+// readiness API handlers and the report generator in pkg/api/componentreadiness. Keeping
+// these separate from the generator package lets them be serialized directly as API
+// responses without pulling in BigQuery client code.
+package componentreport
+
+import (
+	"time"
+
+	"github.com/openshift/sippy/pkg/retry"
+)
+
+// RequestReleaseOptions identifies one release arm (base, sample, or a base override) and
+// the time window within it to query.
+type RequestReleaseOptions struct {
+	Release string
+	Start   time.Time
+	End     time.Time
+}
+
+// RequestTestIdentificationOptions identifies the single test a test-details report is
+// generated for.
+type RequestTestIdentificationOptions struct {
+	Component  string
+	Capability string
+	TestID     string
+}
+
+// VariantSet is a small set of variant group-by keys, e.g. the dbGroupBy list a caller
+// wants held constant when comparing base and sample.
+type VariantSet map[string]struct{}
+
+// List returns the set's members. Order is not significant to callers, which only range
+// over it to validate that each is present in RequestedVariants.
+func (s VariantSet) List() []string {
+	out := make([]string, 0, len(s))
+	for k := range s {
+		out = append(out, k)
+	}
+	return out
+}
+
+// RequestVariantOptions narrows a report to a specific column of variant values, plus the
+// subset of those variants (DBGroupBy) that must all be specified for a test-details drill
+// down to make sense.
+type RequestVariantOptions struct {
+	RequestedVariants map[string]string
+	DBGroupBy         VariantSet
+}
+
+// RequestAdvancedOptions holds knobs that change how a report is computed rather than what
+// it's about: which significance test backend to use, how aggressively to retry transient
+// BigQuery errors, how many BigQuery fetches may run concurrently, and similar tuning
+// parameters most callers leave at their zero value to get the defaults.
+type RequestAdvancedOptions struct {
+	Confidence                  int
+	FlakeAsFailure              bool
+	IncludeMultiReleaseAnalysis bool
+
+	// SignificanceTestBackend selects the SignificanceTest implementation used to decide
+	// whether a sample/base difference is significant. Empty/unrecognized falls back to
+	// Fisher's exact, matching the historical default.
+	SignificanceTestBackend string
+
+	// MaxConcurrentQueries caps how many of the base/sample/override BigQuery fetches run
+	// at once. Zero means "use the generator's default".
+	MaxConcurrentQueries int
+
+	// RetryOptions overrides the default retry budget for transient BigQuery errors. The
+	// zero value means "use retry.DefaultOptions()", so callers like cron report
+	// generation can request a more aggressive budget than the interactive default.
+	RetryOptions retry.Options
+
+	// ExperimentArms lists additional comparison arms -- beyond the built-in "base" and
+	// "baseOverride" derived from RequestOptions.BaseRelease/BaseOverrideRelease -- that a
+	// caller wants fetched from BigQuery and compared against the sample in one report,
+	// e.g. a second base release or a synthetic control population. Each arm is fetched the
+	// same way as "base": by Release/Start/End, with no other plumbing required. Arm Name
+	// must not collide with the reserved "base", "baseOverride", or "sample" names.
+	ExperimentArms []ExperimentArm
+}
+
+// ExperimentArm is one comparison point against the sample: a named release and time
+// window, fetched from BigQuery the same way as the base release.
+type ExperimentArm struct {
+	Name    string
+	Release string
+	Start   time.Time
+	End     time.Time
+}
+
+// RequestOptions is the full input to GetTestDetails: which test, which releases, which
+// variant column, and any advanced tuning.
+type RequestOptions struct {
+	// CacheOption is forwarded as-is to api.GetDataFromCacheOrGenerate; its shape is
+	// defined by that cache layer, not by the report types here.
+	CacheOption         interface{}
+	BaseRelease         RequestReleaseOptions
+	BaseOverrideRelease RequestReleaseOptions
+	SampleRelease       RequestReleaseOptions
+	TestIDOption        RequestTestIdentificationOptions
+	VariantOption       RequestVariantOptions
+	AdvancedOption      RequestAdvancedOptions
+}
+
+// RowIdentification identifies a row of the component readiness matrix.
+type RowIdentification struct {
+	Component  string
+	Capability string
+	TestID     string
+}
+
+// ColumnIdentification identifies a column of the component readiness matrix: the variant
+// values that distinguish it from other columns for the same row.
+type ColumnIdentification struct {
+	Variants map[string]string
+}
+
+// ReportTestIdentification is the full (row, column) identity of a single test's report.
+type ReportTestIdentification struct {
+	RowIdentification
+	ColumnIdentification
+}
+
+// ReportTestStats summarizes a pass/fail/flake comparison between a sample and a base
+// population for one test.
+type ReportTestStats struct {
+	ReportStatus    int
+	Comparison      string
+	SampleStats     TestDetailsTestStats
+	BaseStats       TestDetailsTestStats
+	FisherExact     float64
+	PValue          float64
+	SignificantRate float64
+}
+
+// ReportTestOverride carries the primary base arm's stats alongside a ReportTestDetails
+// generated against the base override, so callers can see both comparisons in one response.
+type ReportTestOverride struct {
+	ReportTestStats ReportTestStats
+	JobStats        []TestDetailsJobStats
+}
+
+// TestDetailsTestStats is the pass/fail/flake breakdown for one population (base or
+// sample) of test runs.
+type TestDetailsTestStats struct {
+	SuccessRate  float64
+	SuccessCount int
+	FailureCount int
+	FlakeCount   int
+}
+
+// TestDetailsJobRunStats is a single job run's outcome, with a link back to its GCS
+// artifacts.
+type TestDetailsJobRunStats struct {
+	TestStats TestDetailsTestStats
+	JobURL    string
+}
+
+// TestDetailsJobStats breaks a test's base/sample comparison down by prow job, including
+// the significance test result computed for that job alone.
+type TestDetailsJobStats struct {
+	JobName           string
+	BaseStats         TestDetailsTestStats
+	SampleStats       TestDetailsTestStats
+	BaseJobRunStats   []TestDetailsJobRunStats
+	SampleJobRunStats []TestDetailsJobRunStats
+	Significant       bool
+	// PValue is the significance test's p-value for this job's sample/base comparison, as
+	// computed by the SignificanceTest backend selected via
+	// RequestAdvancedOptions.SignificanceTestBackend.
+	PValue float64
+}
+
+// ReportTestDetails is the full test-details report for a single test: overall stats plus
+// a per-job breakdown.
+type ReportTestDetails struct {
+	ReportTestIdentification
+
+	JiraComponent   string
+	JiraComponentID *int
+
+	ReportTestStats ReportTestStats
+	JobStats        []TestDetailsJobStats
+
+	// SignificanceTestUsed names the SignificanceTest backend (see
+	// RequestAdvancedOptions.SignificanceTestBackend) used to compute Significant/PValue
+	// on this report and its JobStats, so a reader can tell bootstrap results apart from
+	// Fisher's exact ones.
+	SignificanceTestUsed string
+
+	BaseOverrideReport ReportTestOverride
+
+	GeneratedAt *time.Time
+}
+
+// ArmReport pairs an experiment arm's identity with the ReportTestDetails computed for it.
+type ArmReport struct {
+	Arm    ExperimentArm
+	Report ReportTestDetails
+}
+
+// PairwiseComparison is one entry in a ReportTestExperiment's significance matrix: how
+// ArmA's results compare to ArmB's, via the experiment's significance test backend. "sample"
+// is a valid ArmA/ArmB name alongside the fetched arms, so even a single-base-arm report
+// carries the sample-vs-base comparison that's the point of the whole report.
+type PairwiseComparison struct {
+	ArmA        string
+	ArmB        string
+	PValue      float64
+	Significant bool
+	Effect      float64
+}
+
+// ReportTestExperiment is the N-arm counterpart to ReportTestDetails: one ReportTestStats
+// per arm (control and treatments alike) plus a pairwise significance matrix across the
+// sample and every arm, so a caller can compare a sample against a 4.14 base, a 4.15 base,
+// and a synthetic no-regressions-allowed base in one call.
+type ReportTestExperiment struct {
+	ReportTestIdentification
+	GeneratedAt        *time.Time
+	Arms               []ArmReport
+	SignificanceMatrix []PairwiseComparison
+}
+
+// JobVariants maps a prow job name to the variant key/value pairs BigQuery has recorded
+// for it.
+type JobVariants struct {
+	Variants map[string]map[string]string
+}
+
+// JobRunTestStatusRow is one test's outcome counts for one job run.
+type JobRunTestStatusRow struct {
+	ProwJob         string
+	FilePath        string
+	JiraComponent   string
+	JiraComponentID *int
+	SuccessCount    int
+	FailureCount    int
+	FlakeCount      int
+}
+
+// JobRunTestReportStatus is the raw base/sample (and optional base-override) test status
+// fetched from BigQuery, keyed by prow job name, before it's folded into a
+// ReportTestDetails.
+type JobRunTestReportStatus struct {
+	BaseStatus         map[string][]JobRunTestStatusRow
+	BaseOverrideStatus map[string][]JobRunTestStatusRow
+	SampleStatus       map[string][]JobRunTestStatusRow
+
+	// ExtraArmStatus holds the raw status for any RequestAdvancedOptions.ExperimentArms
+	// fetched alongside base/baseOverride/sample, keyed by ExperimentArm.Name, so a caller
+	// isn't limited to the two built-in arms when comparing against the sample.
+	ExtraArmStatus map[string]map[string][]JobRunTestStatusRow
+
+	GeneratedAt *time.Time
+
+	// RetryCount is how many additional attempts (beyond the first) it took to fetch this
+	// status from BigQuery, per the generator's retry budget. Zero means the first attempt
+	// succeeded. getBaseJobRunTestStatus and getSampleJobRunTestStatus set it on the
+	// per-fetch status they build; the merged status getJobRunTestStatusFromBigQuery
+	// returns does not carry it over, so it is zero there.
+	RetryCount int
+	// LastErrorKind classifies the error of the final fetch attempt. It is empty when that
+	// attempt succeeded, even if earlier attempts failed. Like RetryCount, it is set only on
+	// the per-fetch status and is empty on the merged status.
+	LastErrorKind string
+}
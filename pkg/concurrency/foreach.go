@@ -0,0 +1,51 @@
+// Package concurrency provides small, typed helpers for running bounded sets of
+// goroutines with errgroup semantics, so callers don't have to hand-roll a
+// sync.WaitGroup/error-slice dance for every fan-out.
+package concurrency
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Job is one unit of work for ForEachJob, identified by ID so results can be looked up
+// without relying on slice ordering.
+type Job[T any] struct {
+	ID string
+	Fn func(ctx context.Context) (T, error)
+}
+
+// ForEachJob runs jobs against at most concurrency goroutines at a time (unbounded when
+// concurrency <= 0), cancels sibling jobs on the first error via errgroup.WithContext, and
+// returns results keyed by Job.ID. On error, the returned map contains the result of every
+// job that succeeded, including jobs that finished after the first error.
+func ForEachJob[T any](ctx context.Context, concurrency int, jobs []Job[T]) (map[string]T, error) {
+	results := make(map[string]T, len(jobs))
+	var mu sync.Mutex
+
+	g, gctx := errgroup.WithContext(ctx)
+	if concurrency > 0 {
+		g.SetLimit(concurrency)
+	}
+
+	for _, job := range jobs {
+		job := job
+		g.Go(func() error {
+			result, err := job.Fn(gctx)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			results[job.ID] = result
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachJobCollectsResultsByID(t *testing.T) {
+	jobs := []Job[int]{
+		{ID: "a", Fn: func(ctx context.Context) (int, error) { return 1, nil }},
+		{ID: "b", Fn: func(ctx context.Context) (int, error) { return 2, nil }},
+		{ID: "c", Fn: func(ctx context.Context) (int, error) { return 3, nil }},
+	}
+
+	results, err := ForEachJob(context.Background(), 0, jobs)
+	if err != nil {
+		t.Fatalf("ForEachJob() error = %v, want nil", err)
+	}
+	want := map[string]int{"a": 1, "b": 2, "c": 3}
+	if len(results) != len(want) {
+		t.Fatalf("ForEachJob() = %v, want %v", results, want)
+	}
+	for id, v := range want {
+		if results[id] != v {
+			t.Errorf("results[%q] = %v, want %v", id, results[id], v)
+		}
+	}
+}
+
+func TestForEachJobRespectsConcurrencyLimit(t *testing.T) {
+	const concurrency = 2
+	const jobCount = 10
+
+	var inFlight, maxInFlight int32
+	release := make(chan struct{})
+
+	jobs := make([]Job[struct{}], jobCount)
+	for i := range jobs {
+		jobs[i] = Job[struct{}]{
+			ID: string(rune('a' + i)),
+			Fn: func(ctx context.Context) (struct{}, error) {
+				n := atomic.AddInt32(&inFlight, 1)
+				for {
+					cur := atomic.LoadInt32(&maxInFlight)
+					if n <= cur || atomic.CompareAndSwapInt32(&maxInFlight, cur, n) {
+						break
+					}
+				}
+				<-release
+				atomic.AddInt32(&inFlight, -1)
+				return struct{}{}, nil
+			},
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = ForEachJob(context.Background(), concurrency, jobs)
+		close(done)
+	}()
+
+	// Wait for jobs to actually reach the <-release wait before closing it. Without this,
+	// close(release) can win the race before any job blocks, letting every job run
+	// start-to-finish almost instantly regardless of whether the concurrency cap is wired
+	// correctly -- the assertion below would pass even with a broken cap.
+	deadline := time.After(5 * time.Second)
+	for atomic.LoadInt32(&inFlight) < concurrency {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for %d jobs to be in flight, got %d", concurrency, atomic.LoadInt32(&inFlight))
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	close(release)
+	<-done
+
+	if got := atomic.LoadInt32(&maxInFlight); got > concurrency {
+		t.Errorf("max observed in-flight jobs = %d, want <= %d", got, concurrency)
+	}
+}
+
+func TestForEachJobReturnsFirstErrorAndPartialResults(t *testing.T) {
+	wantErr := errors.New("boom")
+
+	jobs := []Job[int]{
+		{ID: "ok", Fn: func(ctx context.Context) (int, error) { return 1, nil }},
+		{ID: "bad", Fn: func(ctx context.Context) (int, error) { return 0, wantErr }},
+	}
+
+	results, err := ForEachJob(context.Background(), 0, jobs)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("ForEachJob() error = %v, want %v", err, wantErr)
+	}
+	if _, ok := results["bad"]; ok {
+		t.Errorf("results should not contain the failed job's ID, got %v", results)
+	}
+}
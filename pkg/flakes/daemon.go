@@ -0,0 +1,116 @@
+package flakes
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// IssueFiler is the subset of the Jira integration the daemon needs. It's kept narrow and
+// local to this package so flakes doesn't have to import the full Jira client just to file
+// and close issues.
+type IssueFiler interface {
+	// FileOrUpdate creates a Jira issue for the given flaky test if one doesn't already
+	// exist (keyed by test name + release), or updates the existing one, returning its key.
+	FileOrUpdate(test FlakyTest) (issueKey string, err error)
+	// Close transitions the given issue to closed.
+	Close(issueKey string) error
+}
+
+// DaemonOptions configures the auto-create-issues daemon.
+type DaemonOptions struct {
+	Release             string
+	Interval            time.Duration
+	DaysBeforeAutoClose int
+	Detection           DetectionOptions
+}
+
+// RunAutoCreateIssuesDaemon polls DetectFlakyTests on Interval, files/updates a Jira issue
+// per newly- or still-flaky test via filer, and auto-closes issues for tests that have
+// fallen out of the flaky set for DaysBeforeAutoClose days. It blocks until stopCh is
+// closed, so callers should run it in its own goroutine.
+//
+// Nothing in this tree constructs or starts this daemon yet: the sippy command that owns
+// the rest of its background jobs isn't part of this source tree, so wiring up an
+// --auto-create-issues flag (and the real JiraClient it would need) is deferred to a
+// follow-up rather than stubbed out here.
+func RunAutoCreateIssuesDaemon(dbc *db.DB, filer IssueFiler, opts DaemonOptions, stopCh <-chan struct{}) error {
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	if err := runAutoCreateIssuesOnce(dbc, filer, opts); err != nil {
+		log.WithError(err).Error("flaky test auto-create-issues pass failed")
+	}
+
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case <-ticker.C:
+			if err := runAutoCreateIssuesOnce(dbc, filer, opts); err != nil {
+				log.WithError(err).Error("flaky test auto-create-issues pass failed")
+			}
+		}
+	}
+}
+
+func runAutoCreateIssuesOnce(dbc *db.DB, filer IssueFiler, opts DaemonOptions) error {
+	detections, err := DetectFlakyTests(dbc, opts.Release, opts.Detection)
+	if err != nil {
+		return fmt.Errorf("detecting flaky tests: %w", err)
+	}
+
+	if err := PersistDetections(dbc, detections); err != nil {
+		return fmt.Errorf("persisting flaky test detections: %w", err)
+	}
+
+	for _, d := range detections {
+		// detections come fresh from DetectFlakyTests without a JiraIssue; pick up the one
+		// already recorded for this (release, test name) so it's updated, not filed again.
+		var existing FlakyTest
+		if err := dbc.DB.Where("release = ? AND test_name = ?", d.Release, d.TestName).First(&existing).Error; err == nil {
+			d.JiraIssue = existing.JiraIssue
+		}
+		issueKey, err := filer.FileOrUpdate(d)
+		if err != nil {
+			log.WithError(err).WithField("test", d.TestName).Error("failed to file/update Jira issue for flaky test")
+			continue
+		}
+		if err := dbc.DB.Model(&FlakyTest{}).
+			Where("release = ? AND test_name = ?", d.Release, d.TestName).
+			Update("jira_issue", issueKey).Error; err != nil {
+			log.WithError(err).WithField("test", d.TestName).Error("failed to record Jira issue key")
+		}
+	}
+
+	closed, err := CloseStale(dbc, opts.Release, opts.DaysBeforeAutoClose)
+	if err != nil {
+		return fmt.Errorf("closing stale flaky tests: %w", err)
+	}
+
+	var stillOpen []FlakyTest
+	if len(closed) > 0 {
+		if err := dbc.DB.Where("release = ? AND test_name IN ? AND status = ?", opts.Release, closed, StatusClosed).Find(&stillOpen).Error; err != nil {
+			log.WithError(err).Error("failed to load auto-closed flaky tests for Jira close")
+		}
+	}
+	for _, t := range stillOpen {
+		if t.JiraIssue == "" {
+			continue
+		}
+		if err := filer.Close(t.JiraIssue); err != nil {
+			log.WithError(err).WithField("test", t.TestName).Error("failed to close Jira issue for resolved flaky test")
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"release": opts.Release,
+		"flaky":   len(detections),
+		"closed":  len(closed),
+	}).Info("flaky test auto-create-issues pass completed")
+
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package flakes detects tests that flip between pass and fail within a short window of
+// runs ("flaky" tests, as distinct from tests that simply regress) and persists the
+// detections so they can be tracked, filed as Jira issues, and auto-closed over time.
+package flakes
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// FlakyTest is a persisted flaky-test detection, one row per (release, test name).
+type FlakyTest struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Release   string    `json:"release" gorm:"index:idx_flaky_tests_release_name"`
+	TestName  string    `json:"test_name" gorm:"index:idx_flaky_tests_release_name"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	FlipCount int       `json:"flip_count"`
+	TotalRuns int       `json:"total_runs"`
+	FlakeRate float64   `json:"flake_rate"`
+	Variants  []string  `json:"variants" gorm:"type:text[]"`
+	Status    string    `json:"status"` // "open", "closed"
+	JiraIssue string    `json:"jira_issue,omitempty"`
+}
+
+const (
+	StatusOpen   = "open"
+	StatusClosed = "closed"
+)
+
+// DetectionOptions configures how a run flags a test as flaky.
+type DetectionOptions struct {
+	// MaxDays bounds how far back we look for flips when computing the flake rate.
+	MaxDays int
+	// MinFlips is the absolute number of pass<->fail flips required, in addition to
+	// meeting FlakeRateThreshold, before a test is marked flaky. This keeps a test with
+	// a handful of runs and one flip from dominating a rate-only view.
+	MinFlips int
+	// FlakeRateThreshold is flips / total_runs over MaxDays.
+	FlakeRateThreshold float64
+}
+
+// DefaultDetectionOptions returns the default thresholds: a 14-day window, at least 3
+// flips, and a flake rate of at least 10%.
+func DefaultDetectionOptions() DetectionOptions {
+	return DetectionOptions{
+		MaxDays:            14,
+		MinFlips:           3,
+		FlakeRateThreshold: 0.1,
+	}
+}
+
+// runResult is one test execution within a single job run, ordered so flips can be
+// detected by walking adjacent runs for the same test+variant combination.
+type runResult struct {
+	TestName string
+	Variants string
+	Passed   bool
+	Time     time.Time
+}
+
+// DetectFlakyTests scans prow_job_run_tests for the given release over opts.MaxDays,
+// counts pass<->fail flips per test (within a single job run, or across adjacent runs on
+// the same variant), and returns the tests whose flake rate and flip count both clear the
+// configured thresholds.
+func DetectFlakyTests(dbc *db.DB, release string, opts DetectionOptions) ([]FlakyTest, error) {
+	now := time.Now()
+	since := now.Add(-time.Duration(opts.MaxDays) * 24 * time.Hour)
+
+	var runs []runResult
+	res := dbc.DB.Table("prow_job_run_tests").
+		Select("tests.name as test_name, prow_job_run_tests.variants as variants, prow_job_run_tests.status = 1 as passed, prow_job_run_tests.timestamp as time").
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_jobs.release = ?", release).
+		Where("prow_job_run_tests.timestamp >= ?", since).
+		Order("tests.name, prow_job_run_tests.variants, prow_job_run_tests.timestamp").
+		Scan(&runs)
+	if res.Error != nil {
+		log.WithError(res.Error).Error("error querying test runs for flaky detection")
+		return nil, res.Error
+	}
+
+	flaky := accumulateFlakyTests(runs, release, opts)
+
+	log.WithFields(log.Fields{
+		"release": release,
+		"flaky":   len(flaky),
+		"elapsed": time.Since(now),
+	}).Info("flaky test detection completed")
+
+	return flaky, nil
+}
+
+// accumulateFlakyTests counts pass<->fail flips per test from runs (ordered by test name,
+// then variants, then timestamp, as DetectFlakyTests queries them) and returns the tests
+// whose flake rate and flip count both clear opts' thresholds. It's split out from
+// DetectFlakyTests so the counting logic can be unit tested without a database.
+func accumulateFlakyTests(runs []runResult, release string, opts DetectionOptions) []FlakyTest {
+	type accum struct {
+		firstSeen, lastSeen time.Time
+		flips, total        int
+		variants            map[string]bool
+	}
+	byTest := map[string]*accum{}
+
+	var prev *runResult
+	for i := range runs {
+		r := &runs[i]
+		a, ok := byTest[r.TestName]
+		if !ok {
+			a = &accum{firstSeen: r.Time, lastSeen: r.Time, variants: map[string]bool{}}
+			byTest[r.TestName] = a
+		}
+		a.total++
+		a.variants[r.Variants] = true
+		if r.Time.Before(a.firstSeen) {
+			a.firstSeen = r.Time
+		}
+		if r.Time.After(a.lastSeen) {
+			a.lastSeen = r.Time
+		}
+		if prev != nil && prev.TestName == r.TestName && prev.Variants == r.Variants && prev.Passed != r.Passed {
+			a.flips++
+		}
+		prev = r
+	}
+
+	flaky := make([]FlakyTest, 0)
+	for name, a := range byTest {
+		if a.total == 0 {
+			continue
+		}
+		rate := float64(a.flips) / float64(a.total)
+		if a.flips < opts.MinFlips || rate < opts.FlakeRateThreshold {
+			continue
+		}
+		variants := make([]string, 0, len(a.variants))
+		for v := range a.variants {
+			variants = append(variants, v)
+		}
+		flaky = append(flaky, FlakyTest{
+			Release:   release,
+			TestName:  name,
+			FirstSeen: a.firstSeen,
+			LastSeen:  a.lastSeen,
+			FlipCount: a.flips,
+			TotalRuns: a.total,
+			FlakeRate: rate,
+			Variants:  variants,
+			Status:    StatusOpen,
+		})
+	}
+	return flaky
+}
+
+// PersistDetections upserts detections into the flaky_tests table, keyed on
+// (release, test_name), carrying forward the existing JiraIssue so a re-detection doesn't
+// clobber an issue that's already been filed. A re-detected test is stored as open, even
+// if its existing row was closed.
+func PersistDetections(dbc *db.DB, detections []FlakyTest) error {
+	for _, d := range detections {
+		var existing FlakyTest
+		err := dbc.DB.Where("release = ? AND test_name = ?", d.Release, d.TestName).First(&existing).Error
+		if err == nil {
+			d.ID = existing.ID
+			d.JiraIssue = existing.JiraIssue
+			if existing.Status == StatusClosed {
+				d.Status = StatusOpen // a fresh detection means it flaked again
+			}
+		}
+		if err := dbc.DB.Save(&d).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloseStale marks flaky tests as closed once they haven't been re-detected for
+// daysBeforeAutoClose days, returning the test names that were closed.
+func CloseStale(dbc *db.DB, release string, daysBeforeAutoClose int) ([]string, error) {
+	cutoff := time.Now().Add(-time.Duration(daysBeforeAutoClose) * 24 * time.Hour)
+
+	var stale []FlakyTest
+	if err := dbc.DB.Where("release = ? AND status = ? AND last_seen < ?", release, StatusOpen, cutoff).Find(&stale).Error; err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(stale))
+	for _, t := range stale {
+		names = append(names, t.TestName)
+	}
+
+	if len(names) == 0 {
+		return names, nil
+	}
+
+	err := dbc.DB.Model(&FlakyTest{}).
+		Where("release = ? AND status = ? AND last_seen < ?", release, StatusOpen, cutoff).
+		Update("status", StatusClosed).Error
+	return names, err
+}
@@ -0,0 +1,87 @@
+package flakes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccumulateFlakyTestsFlipCounting(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+	opts := DetectionOptions{MinFlips: 2, FlakeRateThreshold: 0.1}
+
+	runs := []runResult{
+		// "flip-test" alternates pass/fail/pass/fail: 3 flips over 4 runs.
+		{TestName: "flip-test", Variants: "amd64", Passed: true, Time: at(0)},
+		{TestName: "flip-test", Variants: "amd64", Passed: false, Time: at(1)},
+		{TestName: "flip-test", Variants: "amd64", Passed: true, Time: at(2)},
+		{TestName: "flip-test", Variants: "amd64", Passed: false, Time: at(3)},
+		// "stable-test" always passes: zero flips.
+		{TestName: "stable-test", Variants: "amd64", Passed: true, Time: at(0)},
+		{TestName: "stable-test", Variants: "amd64", Passed: true, Time: at(1)},
+		{TestName: "stable-test", Variants: "amd64", Passed: true, Time: at(2)},
+		// "cross-variant" fails then passes, but on different variants, so it's not a flip:
+		// adjacency is only meaningful within the same test+variant combination.
+		{TestName: "cross-variant", Variants: "amd64", Passed: false, Time: at(0)},
+		{TestName: "cross-variant", Variants: "arm64", Passed: true, Time: at(1)},
+	}
+
+	flaky := accumulateFlakyTests(runs, "4.20", opts)
+
+	byName := make(map[string]FlakyTest, len(flaky))
+	for _, f := range flaky {
+		byName[f.TestName] = f
+	}
+
+	got, ok := byName["flip-test"]
+	if !ok {
+		t.Fatalf("expected flip-test to be detected as flaky, got %+v", flaky)
+	}
+	if got.FlipCount != 3 {
+		t.Errorf("flip-test FlipCount = %d, want 3", got.FlipCount)
+	}
+	if got.TotalRuns != 4 {
+		t.Errorf("flip-test TotalRuns = %d, want 4", got.TotalRuns)
+	}
+	if !got.FirstSeen.Equal(at(0)) {
+		t.Errorf("flip-test FirstSeen = %v, want %v", got.FirstSeen, at(0))
+	}
+	if !got.LastSeen.Equal(at(3)) {
+		t.Errorf("flip-test LastSeen = %v, want %v", got.LastSeen, at(3))
+	}
+
+	if _, ok := byName["stable-test"]; ok {
+		t.Errorf("stable-test should not be flagged flaky, got %+v", byName["stable-test"])
+	}
+	if _, ok := byName["cross-variant"]; ok {
+		t.Errorf("cross-variant should not count a flip across different variants, got %+v", byName["cross-variant"])
+	}
+}
+
+func TestAccumulateFlakyTestsFirstSeenIsMinAcrossVariants(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+	opts := DetectionOptions{MinFlips: 1, FlakeRateThreshold: 0.1}
+
+	// arm64's rows are listed before amd64's, so the true minimum timestamp (amd64's at(0))
+	// appears after arm64's rows -- firstSeen must still find it.
+	runs := []runResult{
+		{TestName: "multi-variant", Variants: "arm64", Passed: true, Time: at(5)},
+		{TestName: "multi-variant", Variants: "arm64", Passed: false, Time: at(6)},
+		{TestName: "multi-variant", Variants: "amd64", Passed: true, Time: at(0)},
+		{TestName: "multi-variant", Variants: "amd64", Passed: false, Time: at(10)},
+	}
+
+	flaky := accumulateFlakyTests(runs, "4.20", opts)
+	if len(flaky) != 1 {
+		t.Fatalf("expected exactly one flaky test, got %+v", flaky)
+	}
+	if !flaky[0].FirstSeen.Equal(at(0)) {
+		t.Errorf("FirstSeen = %v, want %v (the true min across all variants)", flaky[0].FirstSeen, at(0))
+	}
+	if !flaky[0].LastSeen.Equal(at(10)) {
+		t.Errorf("LastSeen = %v, want %v", flaky[0].LastSeen, at(10))
+	}
+}
@@ -0,0 +1,49 @@
+package flakes
+
+import "fmt"
+
+// JiraClient is the subset of sippy's Jira integration the flaky-test filer needs:
+// creating/updating an issue for a test, and transitioning one to closed. It's defined
+// here (rather than importing the concrete Jira client) so this package only depends on
+// the operations it actually uses.
+type JiraClient interface {
+	CreateIssue(project, summary, description string) (key string, err error)
+	UpdateIssueDescription(key, description string) error
+	CloseIssue(key string) error
+}
+
+// jiraIssueFiler adapts sippy's existing JiraClient to the narrower IssueFiler interface
+// RunAutoCreateIssuesDaemon depends on.
+type jiraIssueFiler struct {
+	client  JiraClient
+	project string
+}
+
+// NewJiraIssueFiler returns an IssueFiler backed by client, filing new issues under
+// project.
+func NewJiraIssueFiler(client JiraClient, project string) IssueFiler {
+	return &jiraIssueFiler{client: client, project: project}
+}
+
+func (f *jiraIssueFiler) FileOrUpdate(test FlakyTest) (string, error) {
+	description := fmt.Sprintf("Test %q is flaky on release %s: %d flips over %d runs (%.1f%% flake rate).",
+		test.TestName, test.Release, test.FlipCount, test.TotalRuns, test.FlakeRate*100)
+
+	if test.JiraIssue != "" {
+		if err := f.client.UpdateIssueDescription(test.JiraIssue, description); err != nil {
+			return "", fmt.Errorf("updating issue %s: %w", test.JiraIssue, err)
+		}
+		return test.JiraIssue, nil
+	}
+
+	summary := fmt.Sprintf("Flaky test: %s (%s)", test.TestName, test.Release)
+	key, err := f.client.CreateIssue(f.project, summary, description)
+	if err != nil {
+		return "", fmt.Errorf("creating issue: %w", err)
+	}
+	return key, nil
+}
+
+func (f *jiraIssueFiler) Close(issueKey string) error {
+	return f.client.CloseIssue(issueKey)
+}
@@ -0,0 +1,89 @@
+// Package retry implements a generic exponential-backoff-with-jitter retry loop, in the
+// style of CockroachDB's job retry design: a capped delay that doubles each attempt, plus
+// a budget on both attempt count and total elapsed time so a flaky dependency can't retry
+// forever.
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Options configures a retry loop.
+type Options struct {
+	InitialDelay time.Duration
+	Multiplier   float64
+	MaxDelay     time.Duration
+	// MaxAttempts caps the number of calls to fn, including the first. Zero means
+	// unbounded (MaxElapsed still applies).
+	MaxAttempts int
+	// MaxElapsed caps the total wall-clock time spent retrying. Zero means unbounded
+	// (MaxAttempts still applies).
+	MaxElapsed time.Duration
+}
+
+// DefaultOptions is a reasonable budget for an interactive request: a handful of
+// attempts, capped at a minute between tries, giving up after five minutes total.
+func DefaultOptions() Options {
+	return Options{
+		InitialDelay: time.Second,
+		Multiplier:   2,
+		MaxDelay:     60 * time.Second,
+		MaxAttempts:  5,
+		MaxElapsed:   5 * time.Minute,
+	}
+}
+
+// Result reports what happened across a retry loop, so a caller can record a degraded
+// attempt (it eventually succeeded, but only after retries) rather than just pass/fail.
+type Result struct {
+	Attempts int
+	LastErr  error
+}
+
+// IsRetryable classifies whether an error is worth retrying at all; Do gives up
+// immediately on errors it returns false for (e.g. permission/syntax errors).
+type IsRetryable func(error) bool
+
+// Do calls fn until it succeeds, isRetryable returns false for its error, or the
+// Options budget (MaxAttempts/MaxElapsed) is exhausted. Delay between attempts grows by
+// Multiplier each time, capped at MaxDelay, with up to 50% jitter.
+func Do(ctx context.Context, opts Options, isRetryable IsRetryable, fn func(ctx context.Context) error) Result {
+	start := time.Now()
+	delay := opts.InitialDelay
+	result := Result{}
+
+	for attempt := 1; ; attempt++ {
+		result.Attempts = attempt
+		err := fn(ctx)
+		if err == nil {
+			result.LastErr = nil
+			return result
+		}
+		result.LastErr = err
+
+		if !isRetryable(err) {
+			return result
+		}
+		if opts.MaxAttempts > 0 && attempt >= opts.MaxAttempts {
+			return result
+		}
+		if opts.MaxElapsed > 0 && time.Since(start) >= opts.MaxElapsed {
+			return result
+		}
+
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay)/2+1))
+		select {
+		case <-ctx.Done():
+			result.LastErr = ctx.Err()
+			return result
+		case <-time.After(wait):
+		}
+
+		delay = time.Duration(float64(delay) * opts.Multiplier)
+		if opts.MaxDelay > 0 && delay > opts.MaxDelay {
+			delay = opts.MaxDelay
+		}
+	}
+}
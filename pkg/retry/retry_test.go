@@ -0,0 +1,109 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func alwaysRetryable(error) bool { return true }
+
+func TestDoSucceedsWithoutRetrying(t *testing.T) {
+	calls := 0
+	result := Do(context.Background(), DefaultOptions(), alwaysRetryable, func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+
+	if result.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1", result.Attempts)
+	}
+	if result.LastErr != nil {
+		t.Errorf("LastErr = %v, want nil", result.LastErr)
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1", calls)
+	}
+}
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	wantErr := errors.New("transient")
+	calls := 0
+	opts := Options{InitialDelay: time.Millisecond, Multiplier: 2, MaxDelay: 10 * time.Millisecond, MaxAttempts: 5}
+
+	result := Do(context.Background(), opts, alwaysRetryable, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return wantErr
+		}
+		return nil
+	})
+
+	if result.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", result.Attempts)
+	}
+	if result.LastErr != nil {
+		t.Errorf("LastErr = %v, want nil after eventual success", result.LastErr)
+	}
+}
+
+func TestDoStopsAtMaxAttempts(t *testing.T) {
+	wantErr := errors.New("always fails")
+	calls := 0
+	opts := Options{InitialDelay: time.Millisecond, Multiplier: 2, MaxDelay: 10 * time.Millisecond, MaxAttempts: 3}
+
+	result := Do(context.Background(), opts, alwaysRetryable, func(ctx context.Context) error {
+		calls++
+		return wantErr
+	})
+
+	if calls != 3 {
+		t.Errorf("fn called %d times, want 3 (MaxAttempts)", calls)
+	}
+	if result.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", result.Attempts)
+	}
+	if !errors.Is(result.LastErr, wantErr) {
+		t.Errorf("LastErr = %v, want %v", result.LastErr, wantErr)
+	}
+}
+
+func TestDoGivesUpImmediatelyOnNonRetryableError(t *testing.T) {
+	wantErr := errors.New("permission denied")
+	calls := 0
+	notRetryable := func(error) bool { return false }
+
+	result := Do(context.Background(), DefaultOptions(), notRetryable, func(ctx context.Context) error {
+		calls++
+		return wantErr
+	})
+
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1 (non-retryable error)", calls)
+	}
+	if result.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1", result.Attempts)
+	}
+}
+
+func TestDoStopsOnContextCancellation(t *testing.T) {
+	opts := Options{InitialDelay: 50 * time.Millisecond, Multiplier: 2, MaxDelay: time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	done := make(chan Result, 1)
+	go func() {
+		done <- Do(ctx, opts, alwaysRetryable, func(ctx context.Context) error {
+			calls++
+			return errors.New("transient")
+		})
+	}()
+
+	cancel()
+	result := <-done
+
+	if !errors.Is(result.LastErr, context.Canceled) {
+		t.Errorf("LastErr = %v, want context.Canceled", result.LastErr)
+	}
+}